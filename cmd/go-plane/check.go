@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 校验单个issue key: 项目和issue必须存在, 且issue未归档
+// Validate a single issue key: the project and issue must exist and the issue must not be archived
+func checkIssueKey(run goplane.Config, key string) (models.Issue, error) {
+	// 查找issue并路由到其所在的工作区
+	// Find the issue and route to its workspace
+	issue, run, err := goplane.LocateIssue(run, key)
+	if err != nil {
+		return models.Issue{}, err
+	}
+
+	details, err := run.Client.GetIssueDetails(run.WorkspaceSlug, issue.Project, issue.ID)
+	if err != nil {
+		return models.Issue{}, err
+	}
+	if details.ArchivedAt != nil {
+		return issue, errors.New(msg("issue_archived", details.ArchivedAt.Format("2006-01-02")))
+	}
+
+	return issue, nil
+}
+
+// 获取要检查的文本: 位置参数 > PLANE_REF > 事件负载
+// Get the text to check: positional args > PLANE_REF > event payload
+func checkSource(config Config, args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, "\n"), nil
+	}
+	if config.ref != "" {
+		return config.ref, nil
+	}
+
+	payload, err := loadEventPayload()
+	if err != nil || payload == nil {
+		return "", err
+	}
+	return payload.refText(), nil
+}
+
+func runCheck(config Config, _ map[string]*string, args []string) error {
+	text, err := checkSource(config, args)
+	if err != nil {
+		return err
+	}
+
+	keys := goplane.IssueKeys(text)
+	if len(keys) == 0 {
+		return errors.New(msg("no_issue_key_referenced"))
+	}
+
+	run := config.clientConfig()
+	invalid := 0
+	for _, key := range keys {
+		issue, err := checkIssueKey(run, key)
+		if err != nil {
+			invalid++
+			fmt.Printf("✗ %s: %v\n", key, err)
+			continue
+		}
+		fmt.Printf("✓ %s %s\n", key, issue.Name)
+	}
+
+	if invalid > 0 {
+		return errors.New(msg("invalid_issue_keys", invalid, len(keys)))
+	}
+	return nil
+}
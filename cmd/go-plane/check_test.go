@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/planetest"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func TestCheckIssueKey(t *testing.T) {
+	archivedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := planetest.NewServer()
+	defer srv.Close()
+	srv.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}, {ID: "p2", Identifier: "OPS"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i1", Name: "Open issue", Project: "p1"}, SequenceID: 1},
+			{Issue: models.Issue{ID: "i2", Name: "Archived issue", Project: "p1"}, SequenceID: 2, ArchivedAt: &archivedAt},
+			{Issue: models.Issue{ID: "i3", Name: "Ops issue", Project: "p2"}, SequenceID: 1},
+		},
+	})
+
+	run := Config{baseURL: srv.BaseURL(), workspaceSlug: "ws", token: "test-token"}.clientConfig()
+
+	tests := []struct {
+		key      string
+		wantName string
+		wantErr  string
+	}{
+		{key: "PROJ-1", wantName: "Open issue"},
+		{key: "PROJ-2", wantErr: "archived"},
+		{key: "OPS-1", wantName: "Ops issue"},
+		{key: "PROJ-3", wantErr: "404"},
+		{key: "PROJ-9", wantErr: "404"},
+		{key: "NOPE-1", wantErr: "project not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			issue, err := checkIssueKey(run, tt.key)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkIssueKey(%s) error = %v", tt.key, err)
+				}
+				if issue.Name != tt.wantName {
+					t.Errorf("Expected issue name %q, got %q", tt.wantName, issue.Name)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkIssueKey(%s) error = %v, want it to contain %q", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunCheckWithoutKeys(t *testing.T) {
+	t.Setenv("GITHUB_EVENT_PATH", "")
+
+	err := runCheck(Config{}, nil, []string{"update readme"})
+	if err == nil || !strings.Contains(err.Error(), "no issue key referenced") {
+		t.Errorf("Expected a missing key error, got %v", err)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"github.com/GeekWorkCode/go-plane/pkg/util"
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 子命令定义
+// Subcommand definition
+type command struct {
+	name  string
+	usage string
+	// 帮助中显示的简介的消息ID
+	// Message ID of the summary shown by help
+	summary string
+	// 注册子命令专用的参数
+	// Register subcommand specific flags
+	flags func(fs *flag.FlagSet, config *Config, opts map[string]*string)
+	run   func(config Config, opts map[string]*string, args []string) error
+	// 不需要连接 Plane, 不检查令牌和工作区
+	// Doesn't need to talk to Plane, so the token and workspace aren't validated
+	local bool
+}
+
+// 子命令列表, 按帮助输出的顺序排列
+// Subcommands, in the order shown by help output
+var commands = []command{
+	{
+		name:    "version",
+		usage:   "version",
+		summary: "command.version",
+		run: func(Config, map[string]*string, []string) error {
+			printVersion()
+			return nil
+		},
+		local: true,
+	},
+	{
+		name:    "comment",
+		usage:   "comment [flags] ISSUE-KEY...",
+		summary: "command.comment",
+		flags: func(fs *flag.FlagSet, config *Config, _ map[string]*string) {
+			stringFlag(fs, &config.comment, "comment", msg("flag.comment"))
+			boolFlag(fs, &config.markdown, "markdown", msg("flag.markdown_comment"))
+		},
+		run: runComment,
+	},
+	{
+		name:    "transition",
+		usage:   "transition [flags] ISSUE-KEY...",
+		summary: "command.transition",
+		flags: func(fs *flag.FlagSet, config *Config, _ map[string]*string) {
+			stringFlag(fs, &config.toState, "to-state", msg("flag.to_state"))
+		},
+		run: runTransition,
+	},
+	{
+		name:    "assign",
+		usage:   "assign [flags] ISSUE-KEY...",
+		summary: "command.assign",
+		flags: func(fs *flag.FlagSet, config *Config, _ map[string]*string) {
+			stringFlag(fs, &config.assignee, "assignee", msg("flag.assignee"))
+		},
+		run: runAssign,
+	},
+	{
+		name:    "link",
+		usage:   "link [flags] ISSUE-KEY...",
+		summary: "command.link",
+		flags: func(fs *flag.FlagSet, _ *Config, opts map[string]*string) {
+			opts["url"] = fs.String("url", "", msg("flag.url"))
+			opts["title"] = fs.String("title", "", msg("flag.title"))
+		},
+		run: runLink,
+	},
+	{
+		name:    "get",
+		usage:   "get [flags] ISSUE-KEY...",
+		summary: "command.get",
+		run:     runGet,
+	},
+	{
+		name:    "create",
+		usage:   "create [flags] -project PROJ -name NAME",
+		summary: "command.create",
+		flags: func(fs *flag.FlagSet, config *Config, opts map[string]*string) {
+			opts["project"] = fs.String("project", "", msg("flag.project"))
+			opts["name"] = fs.String("name", "", msg("flag.name"))
+			opts["description"] = fs.String("description", "", msg("flag.description"))
+			opts["priority"] = fs.String("priority", "", msg("flag.priority"))
+			stringFlag(fs, &config.toState, "state", msg("flag.state"))
+			stringFlag(fs, &config.assignee, "assignee", msg("flag.assignee"))
+			boolFlag(fs, &config.markdown, "markdown", msg("flag.markdown_description"))
+		},
+		run: runCreate,
+	},
+	{
+		name:    "check",
+		usage:   "check [flags] [TEXT...]",
+		summary: "command.check",
+		run:     runCheck,
+	},
+	{
+		name:    "hook",
+		usage:   "hook [flags] install|prepare-commit-msg|commit-msg [ARGS...]",
+		summary: "command.hook",
+		flags: func(fs *flag.FlagSet, config *Config, opts map[string]*string) {
+			opts["command"] = fs.String("command", "", msg("flag.hook_command"))
+			boolOpt(fs, opts, "force", false, msg("flag.force"))
+			boolFlag(fs, &config.offline, "offline", msg("flag.offline"))
+		},
+		run:   runHook,
+		local: true,
+	},
+	{
+		name:    "release-notes",
+		usage:   "release-notes [flags] [TEXT...]",
+		summary: "command.release_notes",
+		flags: func(fs *flag.FlagSet, _ *Config, opts map[string]*string) {
+			opts["range"] = fs.String("range", "", msg("flag.range"))
+			opts["template"] = fs.String("template", "", msg("flag.template"))
+			opts["output"] = fs.String("output", "", msg("flag.output"))
+		},
+		run: runReleaseNotes,
+	},
+	{
+		name:    "serve",
+		usage:   "serve [flags]",
+		summary: "command.serve",
+		flags: func(fs *flag.FlagSet, config *Config, _ map[string]*string) {
+			stringFlag(fs, &config.listenAddr, "addr", msg("flag.addr"))
+			stringFlag(fs, &config.webhookSecret, "secret", msg("flag.secret"))
+			stringFlag(fs, &config.toState, "to-state", msg("flag.to_state"))
+			stringFlag(fs, &config.comment, "comment", msg("flag.comment"))
+			stringFlag(fs, &config.assignee, "assignee", msg("flag.assignee"))
+			boolFlag(fs, &config.markdown, "markdown", msg("flag.markdown_comment"))
+		},
+		run: runServe,
+	},
+}
+
+// 打印版本信息
+// Print version information
+func printVersion() {
+	fmt.Printf("go-plane version %s, commit %s\n", Version, Commit)
+}
+
+// 执行子命令, 返回进程退出码
+// Run a subcommand, returning the process exit code
+func runCommand(args []string, config Config) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s\n\n", msg("unknown_command", name))
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("go-plane "+cmd.name, flag.ContinueOnError)
+	opts := make(map[string]*string)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), msg("usage.command", cmd.usage, msg(cmd.summary)))
+		fs.PrintDefaults()
+	}
+	if cmd.name != "version" {
+		bindCommonFlags(fs, &config)
+	}
+	if cmd.flags != nil {
+		cmd.flags(fs, &config, opts)
+	}
+
+	rest, err := parseInterspersed(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+	if config.debug {
+		logLevel.Set(slog.LevelDebug)
+	}
+	// -token 参数可能带来新的令牌
+	// The -token flag may bring a new token
+	maskSecrets(os.Stdout, util.GetGlobalValue, config.token)
+
+	if !cmd.local {
+		if err := config.validate(); err != nil {
+			fmt.Fprintln(os.Stderr, secrets.redact(msg("error", err)))
+			return 1
+		}
+	}
+	if err := cmd.run(config, opts, rest); err != nil {
+		fmt.Fprintln(os.Stderr, secrets.redact(msg("error", err)))
+		return 1
+	}
+	return 0
+}
+
+// 查找子命令
+// Find a subcommand
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// 打印总体帮助
+// Print overall help
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, msg("usage.title"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, msg("usage.default_flow"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, msg("usage.commands"))
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", cmd.name, msg(cmd.summary))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, msg("usage.command_help"))
+	fmt.Fprintln(w, msg("usage.flags_override"))
+}
+
+// 注册所有子命令共用的连接参数
+// Register connection flags shared by all subcommands
+func bindCommonFlags(fs *flag.FlagSet, config *Config) {
+	stringFlag(fs, &config.baseURL, "base-url", msg("flag.base_url"))
+	stringFlag(fs, &config.token, "token", msg("flag.token"))
+	stringFlag(fs, &config.workspaceSlug, "workspace", msg("flag.workspace"))
+	stringFlag(fs, &config.ref, "ref", msg("flag.ref"))
+	stringFlag(fs, &config.output, "output-format", msg("flag.output_format"))
+	boolFlag(fs, &config.refreshCache, "refresh", msg("flag.refresh"))
+	boolFlag(fs, &config.debug, "debug", msg("flag.debug"))
+}
+
+// 注册覆盖配置项的字符串参数, 帮助中不显示当前值以免泄露令牌
+// Register a string flag overriding a config field; the current value is not shown in help to avoid leaking tokens
+func stringFlag(fs *flag.FlagSet, p *string, name, usage string) {
+	fs.Func(name, usage, func(s string) error {
+		*p = s
+		return nil
+	})
+}
+
+// 注册覆盖配置项的布尔参数
+// Register a boolean flag overriding a config field
+func boolFlag(fs *flag.FlagSet, p *bool, name, usage string) {
+	fs.BoolFunc(name, usage, func(s string) error {
+		*p = util.ToBool(s)
+		return nil
+	})
+}
+
+// 注册子命令专用的布尔参数, 值以 "true"/"false" 存入 opts
+// Register a subcommand specific boolean flag, stored in opts as "true"/"false"
+func boolOpt(fs *flag.FlagSet, opts map[string]*string, name string, value bool, usage string) {
+	s := strconv.FormatBool(value)
+	opts[name] = &s
+	fs.BoolFunc(name, usage, func(v string) error {
+		s = strconv.FormatBool(util.ToBool(v))
+		return nil
+	})
+}
+
+// 解析参数, 允许参数与位置参数交替出现
+// Parse flags, allowing them to be interleaved with positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return rest, nil
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
+
+// 子命令的运行配置: 不应用规则, 引用只保留issue key, 位置参数优先于 PLANE_REF
+// Run configuration of a subcommand: no rules, and a reference reduced to its issue keys; positional arguments take precedence over PLANE_REF
+func commandConfig(config Config, args []string) goplane.Config {
+	ref := config.ref
+	if len(args) > 0 {
+		ref = strings.Join(args, " ")
+	}
+
+	run := config.clientConfig()
+	run.Ref = strings.Join(goplane.IssueKeys(ref), " ")
+	return run
+}
+
+// 执行子命令的单个操作并输出结果
+// Run the single operation of a subcommand and write the results
+func runOperation(config Config, run goplane.Config) error {
+	results, err := goplane.Run(context.Background(), run)
+	if err != nil {
+		return err
+	}
+	return writeResults(config, results)
+}
+
+// 命令行中的issue及其所在工作区
+// An issue of a command and the workspace it belongs to
+type commandIssue struct {
+	models.Issue
+	workspaceSlug string
+}
+
+// 查询命令行中的issue
+// Resolve the issues of a command
+func resolveIssues(config Config, args []string) ([]commandIssue, error) {
+	run := commandConfig(config, args)
+	keys := goplane.IssueKeys(run.Ref)
+	if len(keys) == 0 {
+		return nil, goplane.ErrNoIssueKeys
+	}
+
+	var issues []commandIssue
+	for _, key := range keys {
+		issue, issueRun, err := goplane.LocateIssue(run, key)
+		if err != nil {
+			slog.Warn(msg("log.issue_not_found"), "key", key, "err", err)
+			continue
+		}
+		issues = append(issues, commandIssue{Issue: issue, workspaceSlug: issueRun.WorkspaceSlug})
+	}
+	if len(issues) == 0 {
+		return nil, goplane.ErrNoIssues
+	}
+
+	return issues, nil
+}
+
+func runComment(config Config, _ map[string]*string, args []string) error {
+	if config.comment == "" {
+		return errors.New(msg("flag_required", "comment"))
+	}
+	run := commandConfig(config, args)
+	run.Comment = config.comment
+	run.Markdown = config.markdown
+	run.IssueLinks = config.issueLinks
+	run.ForgeLinks = config.forgeLinks
+	run.Event.RepositoryURL = repositoryURL()
+	return runOperation(config, run)
+}
+
+func runTransition(config Config, _ map[string]*string, args []string) error {
+	if config.toState == "" {
+		return errors.New(msg("flag_required", "to-state"))
+	}
+	run := commandConfig(config, args)
+	run.ToState = config.toState
+	return runOperation(config, run)
+}
+
+func runAssign(config Config, _ map[string]*string, args []string) error {
+	if config.assignee == "" {
+		return errors.New(msg("flag_required", "assignee"))
+	}
+	run := commandConfig(config, args)
+	run.Assignee = config.assignee
+	return runOperation(config, run)
+}
+
+func runLink(config Config, opts map[string]*string, args []string) error {
+	if *opts["url"] == "" {
+		return errors.New(msg("flag_required", "url"))
+	}
+	issues, err := resolveIssues(config, args)
+	if err != nil {
+		return err
+	}
+
+	planeClient := newPlaneClient(config)
+	for _, issue := range issues {
+		linkReq := &api.LinkCreateRequest{
+			Title: *opts["title"],
+			URL:   *opts["url"],
+		}
+		if _, err := planeClient.Links.Create(issue.workspaceSlug, issue.Project, issue.ID, linkReq); err != nil {
+			return fmt.Errorf(msg("add_link_failed"), err)
+		}
+		fmt.Println(msg("link_added", linkReq.URL, issue.ID))
+	}
+	return nil
+}
+
+func runGet(config Config, _ map[string]*string, args []string) error {
+	issues, err := resolveIssues(config, args)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		fmt.Printf("%s\n", issue.Name)
+		fmt.Println(msg("get.id", issue.ID))
+		fmt.Println(msg("get.project", issue.Project))
+		fmt.Println(msg("get.state", issue.State))
+		fmt.Println(msg("get.priority", issue.Priority))
+		fmt.Println(msg("get.updated", issue.UpdatedAt.Format("2006-01-02 15:04:05")))
+	}
+	return nil
+}
+
+func runCreate(config Config, opts map[string]*string, _ []string) error {
+	if *opts["project"] == "" || *opts["name"] == "" {
+		return errors.New(msg("project_and_name_required"))
+	}
+
+	run, project, err := goplane.ProjectConfig(config.clientConfig(), *opts["project"])
+	if err != nil {
+		return err
+	}
+
+	description := *opts["description"]
+	if config.markdown {
+		run.IssueLinks = config.issueLinks
+		run.ForgeLinks = config.forgeLinks
+		run.Event.RepositoryURL = repositoryURL()
+		description = goplane.RenderMarkdown(run, description)
+	}
+
+	createReq := &api.IssueCreateRequest{
+		Name:        *opts["name"],
+		Description: description,
+		StateName:   config.toState,
+		Priority:    *opts["priority"],
+	}
+	if config.assignee != "" {
+		createReq.AssigneeNames = []string{config.assignee}
+	}
+
+	issue, err := newPlaneClient(config).Issues.Create(run.WorkspaceSlug, project.ID, createReq)
+	if err != nil {
+		return fmt.Errorf(msg("create_issue_failed"), err)
+	}
+
+	fmt.Printf("Created issue %s (%s)\n", issue.Name, issue.ID)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config := Config{token: "env-token", workspaceSlug: "env-workspace"}
+	bindCommonFlags(fs, &config)
+	stringFlag(fs, &config.comment, "comment", "")
+
+	rest, err := parseInterspersed(fs, []string{"PROJ-1", "-token", "flag-token", "PROJ-2", "-comment=done", "-debug"})
+	if err != nil {
+		t.Fatalf("parseInterspersed() error = %v", err)
+	}
+
+	if want := []string{"PROJ-1", "PROJ-2"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("Expected positional args %v, got %v", want, rest)
+	}
+	// 参数覆盖环境变量, 未设置的参数保留原值
+	// Flags override env values, unset flags keep them
+	if config.token != "flag-token" {
+		t.Errorf("Expected token to be %s, got %s", "flag-token", config.token)
+	}
+	if config.workspaceSlug != "env-workspace" {
+		t.Errorf("Expected workspaceSlug to be %s, got %s", "env-workspace", config.workspaceSlug)
+	}
+	if config.comment != "done" {
+		t.Errorf("Expected comment to be %s, got %s", "done", config.comment)
+	}
+	if !config.debug {
+		t.Errorf("Expected debug to be %v, got %v", true, config.debug)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "version", args: []string{"version"}, want: 0},
+		{name: "help", args: []string{"--help"}, want: 0},
+		{name: "subcommand help", args: []string{"comment", "-help"}, want: 0},
+		{name: "unknown command", args: []string{"frobnicate"}, want: 2},
+		{name: "missing required flag", args: []string{"transition", "PROJ-1"}, want: 1},
+		{name: "unknown flag", args: []string{"get", "-nope"}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runCommand(tt.args, Config{}); got != tt.want {
+				t.Errorf("runCommand(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintUsage(t *testing.T) {
+	defer setLanguage(messages.Language())
+
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{lang: "en", want: []string{"Usage: go-plane [command] [flags]", "  comment        Add a comment to issues"}},
+		{lang: "zh-CN", want: []string{"用法: go-plane [命令] [参数]", "  comment        为 issue 添加评论"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			setLanguage(tt.lang)
+			var b strings.Builder
+			printUsage(&b)
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("Expected usage to contain %q, got:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunCommandInvalidSettings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(file, []byte("PROJ-1 fix login\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 无效设置只让使用它们的命令失败
+	// Invalid settings only fail the commands using them
+	tests := []struct {
+		name    string
+		offline string
+		args    []string
+		want    int
+	}{
+		{name: "version", args: []string{"version"}, want: 0},
+		{name: "help", args: []string{"--help"}, want: 0},
+		{name: "hook prepare-commit-msg", args: []string{"hook", "prepare-commit-msg", file}, want: 0},
+		{name: "hook commit-msg", offline: "true", args: []string{"hook", "commit-msg", file}, want: 0},
+		{name: "hook commit-msg with invalid PLANE_OFFLINE", offline: "maybe", args: []string{"hook", "commit-msg", file}, want: 1},
+		{name: "Plane command", args: []string{"get", "PROJ-1"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range e2eEnv {
+				t.Setenv(key, "")
+			}
+			t.Setenv("PLANE_TOKEN", "test-token")
+			t.Setenv("PLANE_WORKSPACE_SLUG", "ws")
+			t.Setenv("PLANE_CACHE_TTL", "soon")
+			t.Setenv("PLANE_OFFLINE", tt.offline)
+
+			if got := runCommand(tt.args, loadConfig()); got != tt.want {
+				t.Errorf("runCommand(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
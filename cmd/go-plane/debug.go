@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+)
+
+// 调试输出中需要隐藏值的请求头
+// Request headers whose values are hidden in debug output
+var credentialHeaders = regexp.MustCompile(`(?im)^(X-Api-Key|Authorization):[^\r\n]*`)
+
+// 在调试级别记录请求和响应的 http.RoundTripper, 代替 plane-api-go 的调试输出
+// http.RoundTripper logging requests and responses at debug level, replacing the debug output of plane-api-go
+//
+// plane-api-go 的调试输出直接写到标准输出并包含 X-API-Key 请求头, 这里的输出
+// 经过日志处理器, 并隐藏凭据请求头和已登记的密钥.
+// The debug output of plane-api-go goes straight to stdout and includes the
+// X-API-Key header; this output goes through the log handler, with credential
+// headers and registered secrets hidden.
+type debugTransport struct {
+	base http.RoundTripper
+}
+
+func newDebugTransport(base http.RoundTripper) *debugTransport {
+	return &debugTransport{base: base}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !slog.Default().Enabled(req.Context(), slog.LevelDebug) {
+		return t.base.RoundTrip(req)
+	}
+
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		logDump(req.Context(), "log.http_request", dump)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if dump, err := httputil.DumpResponse(resp, true); err == nil {
+		logDump(req.Context(), "log.http_response", dump)
+	}
+	return resp, nil
+}
+
+// 隐藏凭据后记录请求或响应的内容
+// Log the dump of a request or response with credentials hidden
+func logDump(ctx context.Context, key string, dump []byte) {
+	text := credentialHeaders.ReplaceAllString(string(dump), "$1: "+redacted)
+	slog.DebugContext(ctx, msg(key), "dump", secrets.redact(text))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDebugTransport(t *testing.T) {
+	defer logLevel.Set(logLevel.Level())
+	defer slog.SetDefault(slog.Default())
+	secrets.add("debug-secret")
+
+	tests := []struct {
+		name    string
+		level   slog.Level
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "debug",
+			level:   slog.LevelDebug,
+			want:    []string{`msg="HTTP request"`, `msg="HTTP response"`, "X-Api-Key: ***", "Authorization: ***", "GET /api/v1/projects/", `{\"name\":\"***\"}`},
+			notWant: []string{"debug-secret"},
+		},
+		{
+			name:    "info",
+			level:   slog.LevelInfo,
+			notWant: []string{"HTTP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logLevel.Set(tt.level)
+			var buf bytes.Buffer
+			slog.SetDefault(newLogger(&buf, "text", false))
+
+			base := &stubTransport{results: []func() (*http.Response, error){func() (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Status:     "200 OK",
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader(`{"name":"debug-secret"}`)),
+				}, nil
+			}}}
+			req, _ := http.NewRequest(http.MethodGet, "http://plane.test/api/v1/projects/", nil)
+			req.Header.Set("X-API-Key", "other-token")
+			req.Header.Set("Authorization", "Bearer other-token")
+
+			resp, err := newDebugTransport(base).RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip error = %v", err)
+			}
+			// 记录响应后, 响应体仍可读取
+			// The response body is still readable after it was logged
+			if body, _ := io.ReadAll(resp.Body); string(body) != `{"name":"debug-secret"}` {
+				t.Errorf("Unexpected response body %q", body)
+			}
+
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, got)
+				}
+			}
+			for _, notWant := range append(tt.notWant, "other-token") {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/planetest"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 端到端测试读取的环境变量, 每个测试先清空
+// Environment variables read by the end-to-end tests, cleared before each test
+var e2eEnv = []string{
+	"PLANE_BASE_URL", "PLANE_TOKEN", "PLANE_WORKSPACE_SLUG", "PLANE_REF", "PLANE_TO_STATE",
+	"PLANE_COMMENT", "PLANE_ASSIGNEE", "PLANE_LABELS", "PLANE_MODULE", "PLANE_COMMIT_AUTHOR",
+	"PLANE_CONFIG", "PLANE_OUTPUT", "PLANE_MARKDOWN", "PLANE_DEBUG", "PLANE_CONCURRENCY",
+	"PLANE_CACHE_DIR", "PLANE_CACHE_REFRESH", "PLANE_CACHE_TTL", "PLANE_INSECURE",
+	"PLANE_ISSUE_LINKS", "PLANE_FORGE_LINKS", "PLANE_RETRY_ATTEMPTS", "PLANE_RETRY_TIMEOUT", "PLANE_LISTEN_ADDR",
+	"PLANE_WEBHOOK_SECRET", "PLANE_OFFLINE", "GITHUB_SERVER_URL", "GITEA_SERVER_URL", "GITHUB_EVENT_PATH", "GITHUB_EVENT_NAME",
+	"GITHUB_REF", "GITHUB_HEAD_REF", "GITHUB_SHA", "GITHUB_REPOSITORY", "GITHUB_ACTOR",
+	"GITEA_ACTOR", "PLANE_WORKSPACE_MAP", "PLANE_WORKSPACES", "GITHUB_OUTPUT", "GITHUB_STEP_SUMMARY", "GITEA_STEP_SUMMARY",
+}
+
+// 启动预置两个项目的假 Plane 服务, 并设置连接它的环境变量
+// Start a fake Plane seeded with two projects, and set the environment variables connecting to it
+func newE2EServer(t *testing.T, env map[string]string) *planetest.Server {
+	t.Helper()
+
+	server := planetest.NewServer()
+	t.Cleanup(server.Close)
+	server.Token = "test-token"
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}, {ID: "p2", Identifier: "OPS"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i1", Name: "Login page", Project: "p1", State: "s1"}, SequenceID: 1},
+			{Issue: models.Issue{ID: "i2", Name: "Crash on save", Project: "p1", State: "s1"}, SequenceID: 2},
+		},
+		States: []models.State{
+			{ID: "s1", Name: "Todo", Project: "p1"},
+			{ID: "s2", Name: "In Progress", Project: "p1"},
+			{ID: "s3", Name: "Done", Project: "p1"},
+		},
+		Labels:  []models.Label{{ID: "l1", Name: "released", Project: "p1"}},
+		Members: []models.MemberUser{{ID: "u1", DisplayName: "alice", Email: "alice@example.com"}},
+	})
+
+	for _, key := range e2eEnv {
+		t.Setenv(key, "")
+	}
+	t.Setenv("PLANE_BASE_URL", server.BaseURL())
+	t.Setenv("PLANE_TOKEN", "test-token")
+	t.Setenv("PLANE_WORKSPACE_SLUG", "ws")
+	t.Setenv("PLANE_CACHE_DIR", t.TempDir())
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return server
+}
+
+// 加载配置, 出错时终止测试
+// Load the configuration, failing the test on errors
+func mustLoadConfig(t *testing.T) Config {
+	t.Helper()
+	config := loadConfig()
+	if err := config.settingsErr(); err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	return config
+}
+
+func TestEndToEndDefault(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "output")
+	server := newE2EServer(t, map[string]string{
+		"PLANE_REF":         "PROJ-1 fix login #time 1h 30m\nPROJ-2 PROJ-9 handle crash",
+		"PLANE_COMMENT":     "**Fixed** in main, see OPS-7 and #12",
+		"PLANE_MARKDOWN":    "true",
+		"PLANE_ISSUE_LINKS": "link",
+		"PLANE_TO_STATE":    "Done",
+		"PLANE_ASSIGNEE":    "alice",
+		"PLANE_LABELS":      "released",
+		"PLANE_MODULE":      "v1.0",
+		"GITHUB_ACTOR":      "alice",
+		"GITHUB_REPOSITORY": "org/repo",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_OUTPUT":     output,
+	})
+
+	runDefault(mustLoadConfig(t))
+
+	for _, id := range []string{"i1", "i2"} {
+		issue, _ := server.Issue("ws", id)
+		if issue.State != "s3" {
+			t.Errorf("Expected %s to be Done, got state %s", id, issue.State)
+		}
+		if !reflect.DeepEqual(issue.Assignees, []string{"u1"}) {
+			t.Errorf("Expected %s to be assigned to u1, got %v", id, issue.Assignees)
+		}
+		if !reflect.DeepEqual(issue.Labels, []string{"l1"}) {
+			t.Errorf("Expected %s to be labelled l1, got %v", id, issue.Labels)
+		}
+		comments := server.Comments("ws", id)
+		if len(comments) != 1 || !strings.Contains(comments[0].CommentHTML, "<strong>Fixed</strong>") ||
+			!strings.Contains(comments[0].CommentHTML, `<a href="`+server.URL+`/ws/browse/OPS-7/">OPS-7</a>`) ||
+			!strings.Contains(comments[0].CommentHTML, `<a href="https://github.com/org/repo/issues/12">#12</a>`) {
+			t.Errorf("Unexpected comments on %s: %+v", id, comments)
+		}
+	}
+
+	if worklogs := server.Worklogs("ws", "i1"); len(worklogs) != 1 || worklogs[0].Duration != 90 {
+		t.Errorf("Expected a 90 minute worklog on i1, got %+v", worklogs)
+	}
+	if worklogs := server.Worklogs("ws", "i2"); len(worklogs) != 0 {
+		t.Errorf("Expected no worklog on i2, got %+v", worklogs)
+	}
+
+	modules := server.Modules("ws", "p1")
+	if len(modules) != 1 || modules[0].Name != "v1.0" {
+		t.Fatalf("Expected module v1.0 to be created, got %+v", modules)
+	}
+	if got := server.ModuleIssues("ws", modules[0].ID); !reflect.DeepEqual(got, []string{"i1", "i2"}) {
+		t.Errorf("Expected module issues [i1 i2], got %v", got)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read outputs: %v", err)
+	}
+	for _, want := range []string{"issue_keys=PROJ-1,PROJ-2,PROJ-9\n", "failed_count=1\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected outputs to contain %q, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestEndToEndWorkspaces(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "mapped", env: map[string]string{"PLANE_WORKSPACE_MAP": "INFRA=ops"}},
+		{name: "discovered", env: map[string]string{"PLANE_WORKSPACES": "web,ops"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.env["PLANE_REF"] = "PROJ-1 INFRA-1 rotate certificates"
+			tt.env["PLANE_COMMENT"] = "Deployed"
+			server := newE2EServer(t, tt.env)
+			server.Seed("web", planetest.Fixtures{})
+			server.Seed("ops", planetest.Fixtures{
+				Projects: []models.Project{{ID: "p3", Identifier: "INFRA"}},
+				Issues:   []planetest.Issue{{Issue: models.Issue{ID: "i3", Name: "Expiring certificates", Project: "p3"}, SequenceID: 1}},
+			})
+
+			runDefault(mustLoadConfig(t))
+
+			if comments := server.Comments("ws", "i1"); len(comments) != 1 {
+				t.Errorf("Expected a comment on PROJ-1 in ws, got %+v", comments)
+			}
+			if comments := server.Comments("ops", "i3"); len(comments) != 1 {
+				t.Errorf("Expected a comment on INFRA-1 in ops, got %+v", comments)
+			}
+		})
+	}
+}
+
+func TestEndToEndRules(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "go-plane.yml")
+	rules := `rules:
+  - when:
+      event: push
+      tag: v*
+    state: Done
+    comment: Released in {{tag}}
+  - when:
+      event: push
+    state: In Progress
+`
+	if err := os.WriteFile(configFile, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		ref         string
+		wantState   string
+		wantComment string
+	}{
+		{name: "tag", ref: "refs/tags/v1.2.0", wantState: "s3", wantComment: "Released in v1.2.0"},
+		{name: "branch", ref: "refs/heads/main", wantState: "s2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newE2EServer(t, map[string]string{
+				"PLANE_REF":         "PROJ-1 ship it",
+				"PLANE_CONFIG":      configFile,
+				"GITHUB_EVENT_NAME": "push",
+				"GITHUB_REF":        tt.ref,
+			})
+
+			runDefault(mustLoadConfig(t))
+
+			issue, _ := server.Issue("ws", "i1")
+			if issue.State != tt.wantState {
+				t.Errorf("Expected state %s, got %s", tt.wantState, issue.State)
+			}
+			comments := server.Comments("ws", "i1")
+			if tt.wantComment == "" {
+				if len(comments) != 0 {
+					t.Errorf("Expected no comments, got %+v", comments)
+				}
+				return
+			}
+			if len(comments) != 1 || !strings.Contains(comments[0].CommentHTML, tt.wantComment) {
+				t.Errorf("Expected comment %q, got %+v", tt.wantComment, comments)
+			}
+		})
+	}
+}
+
+func TestEndToEndCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		want   int
+		writes []string
+	}{
+		{
+			name:   "comment",
+			args:   []string{"comment", "-comment", "Deployed", "PROJ-1", "PROJ-2"},
+			writes: []string{"POST /workspaces/ws/projects/p1/issues/i1/comments/", "POST /workspaces/ws/projects/p1/issues/i2/comments/"},
+		},
+		{
+			name:   "transition",
+			args:   []string{"transition", "PROJ-2", "-to-state", "In Progress"},
+			writes: []string{"PATCH /workspaces/ws/projects/p1/issues/i2/"},
+		},
+		{
+			name:   "assign",
+			args:   []string{"assign", "-assignee", "alice", "PROJ-1"},
+			writes: []string{"PATCH /workspaces/ws/projects/p1/issues/i1/"},
+		},
+		{
+			name: "unknown issue",
+			args: []string{"comment", "-comment", "Deployed", "PROJ-9"},
+			want: 1,
+		},
+		{
+			name: "missing token",
+			args: []string{"comment", "-token", "", "-comment", "Deployed", "PROJ-1"},
+			want: 1,
+		},
+		{
+			name: "wrong token",
+			args: []string{"transition", "-token", "wrong", "-to-state", "Done", "PROJ-1"},
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newE2EServer(t, nil)
+
+			if got := runCommand(tt.args, mustLoadConfig(t)); got != tt.want {
+				t.Errorf("runCommand(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+
+			var writes []string
+			for _, r := range server.Writes() {
+				writes = append(writes, r.Method+" "+r.Path)
+			}
+			// 评论并发创建, 比较前排序
+			// Comments are created concurrently, sort before comparing
+			slices.Sort(writes)
+			if !reflect.DeepEqual(writes, tt.writes) {
+				t.Errorf("Expected writes %v, got %v", tt.writes, writes)
+			}
+		})
+	}
+}
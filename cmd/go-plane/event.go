@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+// 事件中的提交
+// Commit inside an event payload
+type eventCommit struct {
+	ID      string               `json:"id"`
+	Message string               `json:"message"`
+	Author  goplane.CommitAuthor `json:"author"`
+}
+
+// GitHub/Gitea 事件负载中使用到的字段
+// Fields of a GitHub/Gitea event payload used by go-plane
+type eventPayload struct {
+	Action      string        `json:"action"`
+	Ref         string        `json:"ref"`
+	After       string        `json:"after"`
+	HeadCommit  *eventCommit  `json:"head_commit"`
+	Commits     []eventCommit `json:"commits"`
+	PullRequest *struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Review *struct {
+		Body string `json:"body"`
+	} `json:"review"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// 事件负载中可能引用issue key的文本: 提交消息、拉取请求标题/正文/分支和评审内容
+// Text of the event payload that may reference issue keys: commit messages, pull request title/body/branch and review body
+func (p *eventPayload) refText() string {
+	var parts []string
+	if p.PullRequest != nil {
+		parts = append(parts, p.PullRequest.Title, p.PullRequest.Head.Ref, p.PullRequest.Body)
+	}
+	if p.Review != nil {
+		parts = append(parts, p.Review.Body)
+	}
+	if commits := p.commitText(); commits != "" {
+		parts = append(parts, commits)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// 一次投递中用于更新issue的文本, 以及是否记录其中的工时; 拉取请求的后续事件不再处理同样的文本:
+// 评审只使用评审内容, 拉取请求只在创建时使用标题/分支/正文, 工时只从提交消息中记录
+// Text of a delivery that issues are updated from, and whether its work time is logged; later events of a pull request
+// don't process the same text again: reviews only use their body, pull requests their title/branch/body when opened,
+// and work time is only logged from commit messages
+func (p *eventPayload) updateRef() (string, bool) {
+	switch {
+	case p.Review != nil:
+		return p.Review.Body, false
+	case p.PullRequest != nil && p.Action == "opened":
+		return strings.Join([]string{p.PullRequest.Title, p.PullRequest.Head.Ref, p.PullRequest.Body}, "\n"), false
+	case p.PullRequest != nil:
+		return "", false
+	default:
+		return p.commitText(), true
+	}
+}
+
+// 事件中的提交消息, 没有提交列表时使用 head commit
+// Commit messages of the event, using the head commit without a commit list
+func (p *eventPayload) commitText() string {
+	var messages []string
+	for _, commit := range p.Commits {
+		messages = append(messages, commit.Message)
+	}
+	if len(p.Commits) == 0 && p.HeadCommit != nil {
+		messages = append(messages, p.HeadCommit.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+// 事件负载中的提交作者
+// Commit author of the event payload
+func (p *eventPayload) author() goplane.CommitAuthor {
+	if p.HeadCommit != nil && p.HeadCommit.Author.Name != "" {
+		return p.HeadCommit.Author
+	}
+	if n := len(p.Commits); n > 0 && p.Commits[n-1].Author.Name != "" {
+		return p.Commits[n-1].Author
+	}
+	if p.Sender.Login != "" {
+		return goplane.CommitAuthor{Name: p.Sender.Login, Username: p.Sender.Login}
+	}
+	return goplane.CommitAuthor{}
+}
+
+// 根据事件名称和负载构建事件上下文
+// Build the event context from the event name and payload
+func (p *eventPayload) context(event string) goplane.EventContext {
+	ctx := goplane.EventContext{
+		Event:         event,
+		Action:        p.Action,
+		SHA:           p.After,
+		Repository:    p.Repository.FullName,
+		RepositoryURL: p.Repository.HTMLURL,
+		Actor:         p.Sender.Login,
+	}
+
+	switch {
+	case strings.HasPrefix(p.Ref, "refs/tags/"):
+		ctx.Tag = strings.TrimPrefix(p.Ref, "refs/tags/")
+	case strings.HasPrefix(p.Ref, "refs/heads/"):
+		ctx.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+	}
+
+	if p.PullRequest != nil {
+		ctx.Branch = p.PullRequest.Head.Ref
+		ctx.SHA = p.PullRequest.Head.SHA
+	}
+
+	return ctx
+}
+
+// 读取 GITHUB_EVENT_PATH 指向的事件负载 (Gitea Actions 同样设置该变量)
+// Load the event payload pointed to by GITHUB_EVENT_PATH (also set by Gitea Actions)
+func loadEventPayload() (*eventPayload, error) {
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(msg("read_event_failed"), err)
+	}
+
+	var payload eventPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf(msg("parse_event_failed"), err)
+	}
+
+	return &payload, nil
+}
+
+// 解析提交作者: 显式配置 > 事件负载中的 head commit > CI 触发者
+// Resolve the commit author: explicit config > head commit of the event payload > CI actor
+func resolveCommitAuthor(config Config) goplane.CommitAuthor {
+	if config.commitAuthor != "" {
+		return goplane.CommitAuthor{Name: config.commitAuthor}
+	}
+
+	if payload, err := loadEventPayload(); err == nil && payload != nil {
+		if author := payload.author(); author.Name != "" {
+			return author
+		}
+	}
+
+	for _, key := range []string{"GITHUB_ACTOR", "GITEA_ACTOR"} {
+		if actor := os.Getenv(key); actor != "" {
+			return goplane.CommitAuthor{Name: actor, Username: actor}
+		}
+	}
+
+	return goplane.CommitAuthor{}
+}
+
+// 从 GitHub/Gitea Actions 的环境变量和事件负载中读取事件上下文
+// Read the event context from GitHub/Gitea Actions environment variables and the event payload
+func loadEventContext() goplane.EventContext {
+	ctx := goplane.EventContext{
+		Event:         os.Getenv("GITHUB_EVENT_NAME"),
+		SHA:           os.Getenv("GITHUB_SHA"),
+		Repository:    os.Getenv("GITHUB_REPOSITORY"),
+		RepositoryURL: repositoryURL(),
+		Actor:         os.Getenv("GITHUB_ACTOR"),
+	}
+
+	ref := os.Getenv("GITHUB_REF")
+	switch {
+	case strings.HasPrefix(ref, "refs/tags/"):
+		ctx.Tag = strings.TrimPrefix(ref, "refs/tags/")
+	case strings.HasPrefix(ref, "refs/heads/"):
+		ctx.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	// 拉取请求使用源分支
+	// Pull requests use the source branch
+	if headRef := os.Getenv("GITHUB_HEAD_REF"); headRef != "" {
+		ctx.Branch = headRef
+	}
+
+	if payload, err := loadEventPayload(); err == nil && payload != nil {
+		ctx.Action = payload.Action
+	}
+
+	return ctx
+}
+
+// 由 CI 环境变量得到仓库的网页地址, 如 https://github.com/org/repo; 未在 CI 中运行时为空
+// Get the web URL of the repository from CI environment variables, e.g. https://github.com/org/repo; empty outside CI
+func repositoryURL() string {
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	if repository == "" {
+		return ""
+	}
+	for _, key := range []string{"GITHUB_SERVER_URL", "GITEA_SERVER_URL"} {
+		if server := os.Getenv(key); server != "" {
+			return strings.TrimRight(server, "/") + "/" + repository
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResolveCommitAuthor(t *testing.T) {
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	t.Setenv("GITHUB_ACTOR", "octocat")
+
+	if got := resolveCommitAuthor(Config{commitAuthor: "Alice"}); got.Name != "Alice" {
+		t.Errorf("Expected explicit author Alice, got %q", got.Name)
+	}
+
+	if got := resolveCommitAuthor(Config{}); got.Username != "octocat" {
+		t.Errorf("Expected actor octocat, got %q", got.Username)
+	}
+}
+
+func TestRepositoryURL(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "outside CI"},
+		{name: "GitHub", env: map[string]string{"GITHUB_REPOSITORY": "org/repo", "GITHUB_SERVER_URL": "https://github.com"}, want: "https://github.com/org/repo"},
+		{name: "Gitea", env: map[string]string{"GITHUB_REPOSITORY": "org/repo", "GITEA_SERVER_URL": "https://gitea.example.com/"}, want: "https://gitea.example.com/org/repo"},
+		{name: "no server", env: map[string]string{"GITHUB_REPOSITORY": "org/repo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"GITHUB_REPOSITORY", "GITHUB_SERVER_URL", "GITEA_SERVER_URL"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := repositoryURL(); got != tt.want {
+				t.Errorf("repositoryURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateRef(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		want         string
+		wantWorklogs bool
+	}{
+		{
+			name:         "push",
+			payload:      `{"commits": [{"message": "PROJ-1 fix #time 1h"}, {"message": "PROJ-2 docs"}]}`,
+			want:         "PROJ-1 fix #time 1h\nPROJ-2 docs",
+			wantWorklogs: true,
+		},
+		{
+			name:         "head commit only",
+			payload:      `{"head_commit": {"message": "PROJ-1 fix"}}`,
+			want:         "PROJ-1 fix",
+			wantWorklogs: true,
+		},
+		{
+			name:    "pull request opened",
+			payload: `{"action": "opened", "pull_request": {"title": "PROJ-1 login", "body": "#time 1h", "head": {"ref": "feature/proj-1"}}}`,
+			want:    "PROJ-1 login\nfeature/proj-1\n#time 1h",
+		},
+		{
+			name:    "pull request synchronized",
+			payload: `{"action": "synchronize", "pull_request": {"title": "PROJ-1 login", "body": "#time 1h", "head": {"ref": "feature/proj-1"}}}`,
+		},
+		{
+			name:    "review",
+			payload: `{"action": "submitted", "review": {"body": "PROJ-2 looks good"}, "pull_request": {"title": "PROJ-1 login", "body": "#time 1h"}}`,
+			want:    "PROJ-2 looks good",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload eventPayload
+			if err := json.Unmarshal([]byte(tt.payload), &payload); err != nil {
+				t.Fatal(err)
+			}
+			got, worklogs := payload.updateRef()
+			if got != tt.want || worklogs != tt.wantWorklogs {
+				t.Errorf("updateRef() = %q, %v, want %q, %v", got, worklogs, tt.want, tt.wantWorklogs)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"github.com/GeekWorkCode/go-plane/pkg/util"
+)
+
+// go-plane 安装的钩子中的标记, 用于识别可以安全覆盖的钩子
+// Marker in hooks installed by go-plane, identifying hooks that are safe to overwrite
+const hookMarker = "# installed by go-plane"
+
+// go-plane 提供的 git 钩子
+// Git hooks provided by go-plane
+var gitHooks = []string{"prepare-commit-msg", "commit-msg"}
+
+// 匹配分支名称中的issue key, 不区分大小写, 例如 feature/proj-12-login
+// Match an issue key in a branch name case-insensitively, e.g. feature/proj-12-login
+var branchKeyRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([a-z][a-z0-9]+-[0-9]+)`)
+
+func runHook(config Config, opts map[string]*string, args []string) error {
+	if len(args) == 0 {
+		return errors.New(msg("hook_usage"))
+	}
+
+	switch args[0] {
+	case "install":
+		return installHooks(*opts["command"], util.ToBool(*opts["force"]))
+	case "prepare-commit-msg":
+		if len(args) < 2 {
+			return errors.New(msg("hook_prepare_usage"))
+		}
+		source := ""
+		if len(args) > 2 {
+			source = args[2]
+		}
+		return prepareCommitMsg(args[1], source, currentBranch())
+	case "commit-msg":
+		if len(args) < 2 {
+			return errors.New(msg("hook_commit_usage"))
+		}
+		if err := config.settingsErr("PLANE_OFFLINE"); err != nil {
+			return fmt.Errorf(msg("invalid_config"), err)
+		}
+		return commitMsg(config, args[1], config.offline)
+	default:
+		return errors.New(msg("unknown_hook_action", args[0]))
+	}
+}
+
+// 安装 git 钩子, 已存在的非 go-plane 钩子需要 -force 才会覆盖
+// Install the git hooks; existing hooks not installed by go-plane are only overwritten with -force
+func installHooks(command string, force bool) error {
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return fmt.Errorf(msg("find_hooks_dir_failed"), err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf(msg("create_hooks_dir_failed"), err)
+	}
+
+	if command == "" {
+		if command, err = os.Executable(); err != nil {
+			command = "go-plane"
+		}
+	}
+
+	for _, hook := range gitHooks {
+		name := filepath.Join(dir, hook)
+		if data, err := os.ReadFile(name); err == nil && !strings.Contains(string(data), hookMarker) && !force {
+			return errors.New(msg("hook_exists", name))
+		}
+
+		script := fmt.Sprintf("#!/bin/sh\n%s\nexec %q hook %s \"$@\"\n", hookMarker, command, hook)
+		if err := os.WriteFile(name, []byte(script), 0o755); err != nil { //nolint:gosec // hooks must be executable
+			return fmt.Errorf(msg("write_hook_failed"), err)
+		}
+		fmt.Println(msg("hook_installed", name))
+	}
+
+	return nil
+}
+
+// 当前分支名称, 分离头指针时返回空字符串
+// Current branch name, empty when HEAD is detached
+func currentBranch() string {
+	out, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// 从分支名称中提取issue key
+// Extract the issue key from a branch name
+func branchIssueKey(branch string) string {
+	match := branchKeyRegex.FindStringSubmatch(branch)
+	if match == nil {
+		return ""
+	}
+	return strings.ToUpper(match[1])
+}
+
+// prepare-commit-msg: 消息中没有issue key时, 在开头加入分支名称中的key
+// prepare-commit-msg: prepend the key from the branch name when the message has none
+func prepareCommitMsg(file, source, branch string) error {
+	// 合并、压缩和修改提交保留原消息
+	// Keep the message of merge, squash and amended commits
+	if source == "merge" || source == "squash" || source == "commit" {
+		return nil
+	}
+
+	key := branchIssueKey(branch)
+	if key == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf(msg("read_commit_msg_failed"), err)
+	}
+	message := string(data)
+	if goplane.IssueKeyPattern.MatchString(stripCommentLines(message)) {
+		return nil
+	}
+
+	if strings.TrimSpace(stripCommentLines(message)) == "" {
+		message = key + " " + message
+	} else {
+		message = key + " " + strings.TrimLeft(message, "\n")
+	}
+
+	return os.WriteFile(file, []byte(message), 0o600)
+}
+
+// commit-msg: 提交消息必须引用存在的issue; 离线或无法连接 Plane 时只检查格式
+// commit-msg: the message must reference an existing issue; offline or when Plane is unreachable, only the pattern is checked
+func commitMsg(config Config, file string, offline bool) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf(msg("read_commit_msg_failed"), err)
+	}
+
+	message := stripCommentLines(string(data))
+	if strings.HasPrefix(message, "Merge ") {
+		return nil
+	}
+
+	keys := goplane.IssueKeys(message)
+	if len(keys) == 0 {
+		return errors.New(msg("commit_msg_no_key"))
+	}
+
+	if offline || config.token == "" || config.workspaceSlug == "" {
+		return nil
+	}
+
+	run := config.clientConfig()
+	for _, key := range keys {
+		_, err := checkIssueKey(run, key)
+		var netErr *url.Error
+		if errors.As(err, &netErr) {
+			slog.Warn(msg("log.plane_unreachable"), "key", key, "err", err)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// 去除 git 提交消息中的注释行
+// Strip comment lines from a git commit message
+func stripCommentLines(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
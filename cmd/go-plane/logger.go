@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/GeekWorkCode/go-plane/pkg/util"
+)
+
+// 当前日志级别, 解析参数后可以调整
+// Current log level, adjustable after flags are parsed
+var logLevel = new(slog.LevelVar)
+
+// PLANE_LOG_LEVEL 和 PLANE_LOG_FORMAT 的可选值
+// Allowed values of PLANE_LOG_LEVEL and PLANE_LOG_FORMAT
+var (
+	logLevels  = []string{"debug", "info", "warn", "warning", "error"}
+	logFormats = []string{"text", "json"}
+)
+
+// 按环境变量配置默认日志记录器
+// Configure the default logger from environment variables
+//
+//	PLANE_LOG_LEVEL  debug, info (默认 / default), warn, error
+//	PLANE_LOG_FORMAT text (默认 / default), json
+//	GITHUB_ACTIONS   为 true 且使用文本格式时, 警告和错误以注解输出 / when true with the text format, warnings and errors are written as annotations
+func setupLogging(w io.Writer, env func(string) string) {
+	// 无效值使用默认值, 由 loadConfig 记录并报告
+	// Invalid values fall back to defaults and are recorded and reported by loadConfig
+	values := util.NewValues(env)
+	logLevel.Set(parseLogLevel(values.Enum("PLANE_LOG_LEVEL", "info", logLevels...)))
+	if values.Bool("PLANE_DEBUG", false) {
+		logLevel.Set(slog.LevelDebug)
+	}
+
+	slog.SetDefault(newLogger(w, values.Enum("PLANE_LOG_FORMAT", "text", logFormats...), env("GITHUB_ACTIONS") == "true"))
+}
+
+func parseLogLevel(s string) slog.Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func newLogger(w io.Writer, format string, actions bool) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: logLevel}
+	if strings.EqualFold(format, "json") {
+		return slog.New(&redactHandler{Handler: slog.NewJSONHandler(w, opts)})
+	}
+
+	var handler slog.Handler = &redactHandler{Handler: slog.NewTextHandler(w, opts)}
+	if actions {
+		return slog.New(&actionsHandler{Handler: handler, out: &lockedWriter{w: w}})
+	}
+	return slog.New(handler)
+}
+
+// 将警告和错误写为 GitHub Actions 注解的日志处理器, 其余级别交给内部处理器
+// Log handler writing warnings and errors as GitHub Actions annotations, passing other levels to the inner handler
+type actionsHandler struct {
+	slog.Handler
+	out   *lockedWriter
+	attrs []slog.Attr
+}
+
+func (h *actionsHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	command := "warning"
+	if r.Level >= slog.LevelError {
+		command = "error"
+	}
+
+	// 以issue key作为注解标题, 其余字段附加在消息后
+	// Use the issue key as the annotation title and append the other fields to the message
+	var title string
+	var fields []string
+	addAttr := func(a slog.Attr) bool {
+		if a.Key == "key" && title == "" {
+			title = a.Value.String()
+		} else {
+			fields = append(fields, a.Key+"="+a.Value.String())
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		addAttr(a)
+	}
+	r.Attrs(addAttr)
+
+	message := r.Message
+	if len(fields) > 0 {
+		message += " (" + strings.Join(fields, ", ") + ")"
+	}
+
+	properties := ""
+	if title != "" {
+		properties = " title=" + escapeWorkflowProperty(title)
+	}
+	h.out.printf("::%s%s::%s\n", command, properties, escapeWorkflowData(message))
+	return nil
+}
+
+// 开始一个可折叠的日志分组, 返回结束分组的函数, 实现 goplane.GroupHandler
+// Start a collapsible log group, returning the function that ends it; implements goplane.GroupHandler
+func (h *actionsHandler) Group(title string) func() {
+	h.out.printf("::group::%s\n", escapeWorkflowData(title))
+	return func() { h.out.printf("::endgroup::\n") }
+}
+
+func (h *actionsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &actionsHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		out:     h.out,
+		attrs:   append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
+	}
+}
+
+func (h *actionsHandler) WithGroup(name string) slog.Handler {
+	return &actionsHandler{Handler: h.Handler.WithGroup(name), out: h.out, attrs: h.attrs}
+}
+
+// 并发安全的输出, 写入前替换密钥
+// Output safe for concurrent use, replacing secrets before writing
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, secrets.redact(fmt.Sprintf(format, args...)))
+}
+
+// 转义工作流命令中的消息
+// Escape the message of a workflow command
+func escapeWorkflowData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// 转义工作流命令中的属性值
+// Escape a property value of a workflow command
+func escapeWorkflowProperty(s string) string {
+	s = escapeWorkflowData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
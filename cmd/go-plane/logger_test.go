@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+func TestLogger(t *testing.T) {
+	defer logLevel.Set(logLevel.Level())
+
+	tests := []struct {
+		name    string
+		format  string
+		actions bool
+		level   slog.Level
+		log     func(l *slog.Logger)
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "text",
+			log: func(l *slog.Logger) {
+				l.Info("found issue", "key", "PROJ-1", "issue", "i1")
+			},
+			want: []string{"level=INFO", `msg="found issue"`, "key=PROJ-1", "issue=i1"},
+		},
+		{
+			name:   "json",
+			format: "json",
+			log: func(l *slog.Logger) {
+				l.Warn("could not find issue", "key", "PROJ-1")
+			},
+			want: []string{`"level":"WARN"`, `"msg":"could not find issue"`, `"key":"PROJ-1"`},
+		},
+		{
+			name:  "level filter",
+			level: slog.LevelWarn,
+			log: func(l *slog.Logger) {
+				l.Info("hidden")
+				l.Warn("shown")
+			},
+			want:    []string{"msg=shown"},
+			notWant: []string{"hidden"},
+		},
+		{
+			name:    "actions annotations",
+			actions: true,
+			log: func(l *slog.Logger) {
+				l.Info("found issue", "key", "PROJ-1")
+				l.Warn("could not find issue", "key", "PROJ-2", "err", errors.New("404"))
+				l.With("op", "state").Error("update failed\n50% done")
+			},
+			want: []string{
+				"level=INFO",
+				"::warning title=PROJ-2::could not find issue (err=404)\n",
+				"::error::update failed%0A50%25 done (op=state)\n",
+			},
+			notWant: []string{"level=WARN", "level=ERROR"},
+		},
+		{
+			name:    "json under actions",
+			format:  "json",
+			actions: true,
+			log: func(l *slog.Logger) {
+				l.Warn("could not find issue", "key", "PROJ-2")
+			},
+			want:    []string{`"level":"WARN"`},
+			notWant: []string{"::warning"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logLevel.Set(tt.level)
+			var buf bytes.Buffer
+			tt.log(newLogger(&buf, tt.format, tt.actions))
+
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", notWant, got)
+				}
+			}
+			if tt.format == "json" && !json.Valid(bytes.TrimSpace(buf.Bytes())) {
+				t.Errorf("Expected valid JSON, got:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestLogGroup(t *testing.T) {
+	var buf bytes.Buffer
+	if _, ok := newLogger(&buf, "text", false).Handler().(goplane.GroupHandler); ok {
+		t.Error("Expected no groups outside Actions")
+	}
+
+	logger := newLogger(&buf, "text", true)
+	h, ok := logger.Handler().(goplane.GroupHandler)
+	if !ok {
+		t.Fatal("Expected the Actions handler to support groups")
+	}
+	end := h.Group("Resolve issues")
+	logger.Info("found issue")
+	end()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "::group::Resolve issues" || lines[2] != "::endgroup::" {
+		t.Errorf("Unexpected group output:\n%s", buf.String())
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+	}
+
+	for value, want := range tests {
+		if got := parseLogLevel(value); got != want {
+			t.Errorf("parseLogLevel(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestSetupLoggingDebug(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer logLevel.Set(logLevel.Level())
+
+	// PLANE_DEBUG 与其它布尔设置的解析方式相同
+	// PLANE_DEBUG is parsed like the other boolean settings
+	tests := map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"true":  slog.LevelDebug,
+		"TRUE":  slog.LevelDebug,
+		"1":     slog.LevelDebug,
+		"false": slog.LevelInfo,
+		"maybe": slog.LevelInfo,
+	}
+
+	for value, want := range tests {
+		setupLogging(&bytes.Buffer{}, func(key string) string {
+			if key == "PLANE_DEBUG" {
+				return value
+			}
+			return ""
+		})
+		if got := logLevel.Level(); got != want {
+			t.Errorf("PLANE_DEBUG=%q: level = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestSetupLoggingLevel(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	defer logLevel.Set(logLevel.Level())
+
+	// 无效值使用默认级别
+	// Invalid values fall back to the default level
+	tests := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"WARN":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+	}
+
+	for value, want := range tests {
+		setupLogging(&bytes.Buffer{}, func(key string) string {
+			if key == "PLANE_LOG_LEVEL" {
+				return value
+			}
+			return ""
+		})
+		if got := logLevel.Level(); got != want {
+			t.Errorf("PLANE_LOG_LEVEL=%q: level = %v, want %v", value, got, want)
+		}
+	}
+}
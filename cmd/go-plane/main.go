@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
-	"regexp"
-	"strings"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
-	"github.com/GeekWorkCode/go-plane/pkg/markdown"
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"github.com/GeekWorkCode/go-plane/pkg/i18n"
 	"github.com/GeekWorkCode/go-plane/pkg/util"
 	plane "github.com/GeekWorkCode/plane-api-go"
-	"github.com/GeekWorkCode/plane-api-go/api"
-	"github.com/GeekWorkCode/plane-api-go/models"
 	"github.com/joho/godotenv"
 )
 
@@ -21,189 +25,97 @@ var Version string
 // Commit ID for command
 var Commit string
 
-type User struct {
-	ID          string `json:"id"`
-	Email       string `json:"email"`
-	Username    string `json:"username"`
-	DisplayName string `json:"display_name"`
-}
-
 func main() {
 	// 加载环境变量(从.env文件)
 	// Load environment variables (from .env file)
-	if err := godotenv.Load(); err != nil {
+	envErr := godotenv.Load()
+	setLanguage(i18n.Detect(util.GetGlobalValue))
+	setupLogging(os.Stderr, util.GetGlobalValue)
+	if envErr != nil {
 		// 仅在开发环境中记录错误，生产环境中这不是问题
 		// Only log this in development, not an error in production
-		log.Printf("Info: .env file not found, using environment variables")
+		slog.Debug(msg("log.env_not_found"))
 	}
 
+	// 无效设置由使用它们的命令报告, 以免影响 version、help 和 git 钩子
+	// Invalid settings are reported by the commands using them, so they don't break version, help and the git hooks
 	config := loadConfig()
-
-	// 创建Plane客户端
-	// Create Plane client
-	planeClient := plane.NewClient(config.token)
-	planeClient.SetDebug(config.debug)
-	if config.baseURL != "" {
-		planeClient.SetBaseURL(config.baseURL)
-	}
-
-	// 如果没有ref，则打印版本并退出
-	// If no ref, print version and exit
-	if config.ref == "" {
-		fmt.Printf("go-plane version %s, commit %s\n", Version, Commit)
-		os.Exit(0)
-	}
-
-	// 解析引用中的issue key
-	// Parse issue key from reference
-	// 尝试从提交消息中提取 WORDS-1 格式的问题key
-	// Try to extract issue key in WORDS-1 format from commit message
-	issueKeyPattern := `([A-Z][A-Z0-9]+-[0-9]+)`
-	re := regexp.MustCompile(issueKeyPattern)
-	matches := re.FindAllString(config.ref, -1)
-
-	if len(matches) == 0 {
-		log.Println("未找到issue keys")
-		log.Println("No issue keys found")
-		os.Exit(0)
-	}
-
-	// 使用第一个匹配到的issue key
-	// Use the first matched issue key
-	parts := strings.Split(matches[0], "-")
-	if len(parts) < 2 {
-		log.Println("无效的issue key格式")
-		log.Println("Invalid issue key format")
-		os.Exit(0)
+	maskSecrets(os.Stdout, util.GetGlobalValue, config.token, config.webhookSecret)
+	if config.debug {
+		logLevel.Set(slog.LevelDebug)
 	}
 
-	// 提取项目标识符和问题序列ID
-	// Extract project identifier and issue sequence ID
-	projectIdentifier := parts[0]
-	sequenceID := parts[1]
-
-	log.Printf("处理issue: %s-%s\n", projectIdentifier, sequenceID)
-	log.Printf("Processing issue: %s-%s\n", projectIdentifier, sequenceID)
+	// Plane 客户端使用默认传输, 在此加入调试输出和重试
+	// The Plane clients use the default transport, add debug output and retries to it
+	http.DefaultTransport = newRetryTransport(newDebugTransport(http.DefaultTransport), config.retry)
 
-	// 获取当前用户信息 - 假设我们无法直接获取
-	// Get current user information - assume we can't directly get it
-	// In a real implementation, we would add error handling for this placeholder
-	self := &User{
-		ID:          "current-user",
-		Email:       "user@example.com",
-		Username:    "current-user",
-		DisplayName: "Current User",
-	}
-	log.Printf("当前用户: %s\n", self.Email)
-	log.Printf("Current user: %s\n", self.Email)
-
-	// 获取要分配的用户
-	// Get user to assign - this is a placeholder since we don't have User API access
-	var assigneeUser *User
-	if config.assignee != "" {
-		assigneeUser = &User{
-			ID:          config.assignee,
-			Email:       config.assignee + "@example.com",
-			Username:    config.assignee,
-			DisplayName: config.assignee,
-		}
+	// 带参数时按子命令执行
+	// Run as a subcommand when arguments are given
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], config))
 	}
 
-	// 处理issue
-	// Process issue
-	issues := processIssue(planeClient, config, projectIdentifier, sequenceID)
-	if len(issues) == 0 {
-		log.Println("未找到issues")
-		log.Println("No issues found")
-		os.Exit(0)
-	}
-
-	// 添加评论
-	// Add comments
-	if config.comment != "" {
-		addComments(planeClient, config, issues, self)
-	}
+	runDefault(config)
+}
 
-	// 更新状态
-	// Update state
-	if config.toState != "" {
-		processState(planeClient, config, issues)
-	}
+// 创建 go-plane 使用的Plane客户端
+// Create the Plane client used by go-plane
+func newClient(config Config) goplane.Client {
+	// 调试输出由 debugTransport 记录
+	// Debug output is logged by debugTransport
+	return goplane.NewClient(config.baseURL, config.token)
+}
 
-	// 分配责任人
-	// Assign issues
-	if assigneeUser != nil {
-		processAssignee(planeClient, config, issues, assigneeUser)
+// 创建完整的Plane客户端, 用于 goplane.Client 之外的请求
+// Create the full Plane client, for requests beyond goplane.Client
+func newPlaneClient(config Config) *plane.Plane {
+	planeClient := plane.NewClient(config.token)
+	if config.baseURL != "" {
+		planeClient.SetBaseURL(config.baseURL)
 	}
+	return planeClient
 }
 
-// 处理单个issue
-// Process single issue
-func processIssue(planeClient *plane.Plane, config Config, projectIdentifier, sequenceID string) []models.Issue {
-	var issues []models.Issue
-
-	// 验证项目是否存在
-	// Verify project exists
-	_, err := findProjectByIdentifier(planeClient, config.workspaceSlug, projectIdentifier)
-	if err != nil {
-		log.Printf("警告: 无法找到项目 '%s': %v\n", projectIdentifier, err)
-		log.Printf("Warning: Could not find project '%s': %v\n", projectIdentifier, err)
-		return issues
+// 以环境变量驱动的默认流程 (GitHub/Gitea Action)
+// Default env-driven flow (GitHub/Gitea Action)
+func runDefault(config Config) {
+	// 如果没有ref，则打印版本并退出
+	// If no ref, print version and exit
+	if config.ref == "" {
+		printVersion()
+		os.Exit(0)
 	}
-
-	issue, err := findIssueBySequenceID(planeClient, config.workspaceSlug, sequenceID)
-	if err != nil {
-		log.Printf("警告: 无法找到issue '%s-%s': %v\n", projectIdentifier, sequenceID, err)
-		log.Printf("Warning: Could not find issue '%s-%s': %v\n", projectIdentifier, sequenceID, err)
-		return issues
+	if err := config.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, secrets.redact(msg("error", err)))
+		os.Exit(1)
 	}
 
-	issues = append(issues, issue)
-	log.Printf("找到issue: %s-%s (%s) - %s\n", projectIdentifier, sequenceID, issue.ID, issue.Name)
-	log.Printf("Found issue: %s-%s (%s) - %s\n", projectIdentifier, sequenceID, issue.ID, issue.Name)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopRetriesOn(ctx)
 
-	return issues
-}
-
-// 处理issue分配
-// Process issue assignment
-func processAssignee(planeClient *plane.Plane, config Config, issues []models.Issue, assignee *User) {
-	for _, issue := range issues {
-		log.Printf("将issue %s 分配给 %s\n", issue.ID, assignee.DisplayName)
-		log.Printf("Assigning issue %s to %s\n", issue.ID, assignee.DisplayName)
-
-		// 使用分配人名称更新问题
-		// Update issue using assignee name
-		updateReq := &api.IssueUpdateRequest{
-			AssigneeNames: []string{assignee.DisplayName},
-		}
+	run := config.pipelineConfig()
+	run.Event = loadEventContext()
+	run.Author = resolveCommitAuthor(config)
 
-		_, err := planeClient.Issues.Update(config.workspaceSlug, issue.Project, issue.ID, updateReq)
-		if err != nil {
-			log.Printf("分配issue失败: %v\n", err)
-			log.Printf("Failed to assign issue: %v\n", err)
-		}
+	results, err := goplane.Run(ctx, run)
+	logRunError(err)
+	if err := writeResults(config, results); err != nil {
+		slog.Error(msg("log.write_results_failed"), "err", err)
 	}
 }
 
-// 处理issue状态更新
-// Process issue state update
-func processState(planeClient *plane.Plane, config Config, issues []models.Issue) {
-	for _, issue := range issues {
-		log.Printf("将issue %s 状态更新为 %s\n", issue.ID, config.toState)
-		log.Printf("Updating issue %s state to %s\n", issue.ID, config.toState)
-
-		// 使用状态名称更新问题
-		// Update issue using state name
-		updateReq := &api.IssueUpdateRequest{
-			StateName: config.toState,
-		}
-
-		_, err := planeClient.Issues.Update(config.workspaceSlug, issue.Project, issue.ID, updateReq)
-		if err != nil {
-			log.Printf("更新状态失败: %v\n", err)
-			log.Printf("Failed to update state: %v\n", err)
-		}
+// 记录运行错误; 未引用或未找到issue不是失败
+// Log the error of a run; referencing or finding no issues isn't a failure
+func logRunError(err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, goplane.ErrNoIssueKeys):
+		slog.Info(msg("log.no_issue_keys"))
+	case errors.Is(err, goplane.ErrNoIssues):
+		slog.Warn(msg("log.no_issues"))
+	default:
+		slog.Error(msg("log.run_failed"), "err", err)
 	}
 }
 
@@ -211,91 +123,181 @@ func processState(planeClient *plane.Plane, config Config, issues []models.Issue
 // Configuration struct
 type Config struct {
 	baseURL       string
-	insecure      string
+	insecure      bool
 	token         string
 	workspaceSlug string
+	workspaceMap  map[string]string
+	workspaces    []string
 	ref           string
 	toState       string
 	comment       string
 	assignee      string
+	labels        []string
+	module        string
+	commitAuthor  string
+	configFile    string
+	output        string
+	webhookSecret string
+	listenAddr    string
 	markdown      bool
+	issueLinks    string
+	forgeLinks    bool
 	debug         bool
+	rules         []goplane.Rule
+	defaults      goplane.Rule
+	retry         retryPolicy
+	concurrency   int
+	cache         *goplane.Cache
+	refreshCache  bool
+	offline       bool
+
+	// 读取设置时发现的问题, 见 settingsErr
+	// Problems found reading the settings, see settingsErr
+	values *util.Values
 }
 
-// 加载配置
-// Load configuration
+// 加载配置, 优先级从高到低: 参数 (由子命令应用), INPUT_*, 环境变量, .env 和配置文件, 默认值;
+// 无效值使用默认值, 其错误由 settingsErr 返回
+// Load configuration with the precedence flags (applied by subcommands) > INPUT_* > environment >
+// .env and config file > defaults; invalid values fall back to defaults, with their errors returned by settingsErr
 func loadConfig() Config {
-	return Config{
-		baseURL:       util.GetGlobalValue("PLANE_BASE_URL"),
-		insecure:      util.GetGlobalValue("PLANE_INSECURE"),
-		token:         util.GetGlobalValue("PLANE_TOKEN"),
-		workspaceSlug: util.GetGlobalValue("PLANE_WORKSPACE_SLUG"),
-		ref:           util.GetGlobalValue("PLANE_REF"),
-		toState:       util.GetGlobalValue("PLANE_TO_STATE"),
-		comment:       util.GetGlobalValue("PLANE_COMMENT"),
-		assignee:      util.GetGlobalValue("PLANE_ASSIGNEE"),
-		markdown:      util.ToBool(util.GetGlobalValue("PLANE_MARKDOWN")),
-		debug:         util.ToBool(util.GetGlobalValue("PLANE_DEBUG")),
-	}
-}
-
-// 根据项目标识符查找项目
-// Find project by identifier
-func findProjectByIdentifier(planeClient *plane.Plane, workspaceSlug, identifier string) (models.Project, error) {
-	projects, err := planeClient.Projects.List(workspaceSlug)
+	// 合并仓库配置文件, 它在读取 PLANE_CONFIG 之后才加载
+	// Merge the repository configuration file, which is only loaded after PLANE_CONFIG is read
+	var file *configFile
+	values := util.NewGlobalValues(func(key string) string { return file.lookup(key) })
+	configPath := values.String("PLANE_CONFIG", "")
+	file, err := loadConfigFile(configPath)
 	if err != nil {
-		return models.Project{}, fmt.Errorf("获取项目列表失败: %w", err)
+		slog.Warn(msg("log.load_config_failed"), "err", err)
 	}
 
-	for _, project := range projects {
-		if strings.EqualFold(project.Identifier, identifier) {
-			return project, nil
-		}
+	config := Config{
+		baseURL:       values.String("PLANE_BASE_URL", ""),
+		insecure:      values.Bool("PLANE_INSECURE", false),
+		token:         values.String("PLANE_TOKEN", ""),
+		workspaceSlug: values.String("PLANE_WORKSPACE_SLUG", ""),
+		workspaceMap:  values.Map("PLANE_WORKSPACE_MAP"),
+		workspaces:    values.List("PLANE_WORKSPACES", nil),
+		ref:           values.String("PLANE_REF", ""),
+		toState:       values.String("PLANE_TO_STATE", ""),
+		comment:       values.String("PLANE_COMMENT", ""),
+		assignee:      values.String("PLANE_ASSIGNEE", ""),
+		labels:        values.List("PLANE_LABELS", nil),
+		module:        values.String("PLANE_MODULE", ""),
+		commitAuthor:  values.String("PLANE_COMMIT_AUTHOR", ""),
+		configFile:    configPath,
+		output:        values.Enum("PLANE_OUTPUT", "text", "text", "json"),
+		webhookSecret: values.String("PLANE_WEBHOOK_SECRET", ""),
+		listenAddr:    values.String("PLANE_LISTEN_ADDR", ""),
+		markdown:      values.Bool("PLANE_MARKDOWN", false),
+		issueLinks:    values.Enum("PLANE_ISSUE_LINKS", "", goplane.IssueLinksURL, goplane.IssueLinksMention),
+		forgeLinks:    values.Bool("PLANE_FORGE_LINKS", true),
+		debug:         values.Bool("PLANE_DEBUG", false),
+		retry:         loadRetryPolicy(values),
+		concurrency:   values.Int("PLANE_CONCURRENCY", goplane.DefaultConcurrency, 1),
+		cache:         loadPlaneCache(values),
+		refreshCache:  values.Bool("PLANE_CACHE_REFRESH", false),
+		offline:       values.Bool("PLANE_OFFLINE", false),
+		values:        values,
 	}
+	if file != nil {
+		config.rules = file.Rules
+	}
+	config.defaults = file.defaults()
+
+	// 日志设置已由 setupLogging 应用, 在此读取以报告其无效值
+	// The logging settings were applied by setupLogging, read them here to report their invalid values
+	values.Enum("PLANE_LOG_LEVEL", "info", logLevels...)
+	values.Enum("PLANE_LOG_FORMAT", "text", logFormats...)
 
-	return models.Project{}, fmt.Errorf("未找到项目: %s", identifier)
+	return config
 }
 
-// 根据序列ID查找issue
-// Find issue by sequence ID
-func findIssueBySequenceID(planeClient *plane.Plane, workspaceSlug, sequenceID string) (models.Issue, error) {
-	// 直接使用序列ID查询issue
-	// Directly query issue by sequence ID
-	issue, err := planeClient.Issues.GetBySequenceID(workspaceSlug, sequenceID)
-	if err != nil {
-		return models.Issue{}, fmt.Errorf("通过序列ID获取issue失败: %w", err)
+// 读取设置时发现的问题, 例如无效值和无法读取的 *_FILE 文件; 未给出 keys 时返回全部
+// Problems found reading the settings, such as invalid values and unreadable *_FILE files; all of them without keys
+func (config Config) settingsErr(keys ...string) error {
+	if config.values == nil {
+		return nil
 	}
 
-	return *issue, nil
+	// 按当前语言重新描述无效值
+	// Describe invalid values again in the current language
+	var errs []error
+	for _, err := range config.values.Errors(keys...) {
+		var valueErr *util.ValueError
+		if errors.As(err, &valueErr) {
+			err = errors.New(msg("invalid_setting", valueErr.Key, valueErr.Value, msg("setting."+valueErr.Reason, valueErr.Args...)))
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
 
-// 添加评论
-// Add comments
-func addComments(planeClient *plane.Plane, config Config, issues []models.Issue, user *User) {
-	for _, issue := range issues {
-		var commentText string
-		if config.markdown {
-			// 将Markdown转换为HTML
-			// Convert Markdown to HTML
-			commentText = markdown.ToHTML(config.comment)
-		} else {
-			commentText = config.comment
+// 检查连接 Plane 所需的设置和无效设置, 一次返回所有问题
+// Check the settings needed to talk to Plane and report invalid settings, returning all problems at once
+func (config Config) validate() error {
+	errs := []error{config.settingsErr()}
+	if config.token == "" {
+		errs = append(errs, fmt.Errorf(msg("setting_required"), "PLANE_TOKEN"))
+	}
+	if config.workspaceSlug == "" {
+		errs = append(errs, fmt.Errorf(msg("setting_required"), "PLANE_WORKSPACE_SLUG"))
+	}
+	if config.baseURL != "" {
+		if u, err := url.Parse(config.baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf(msg("invalid_base_url"), config.baseURL))
 		}
+	}
 
-		log.Printf("为issue %s 添加评论\n", issue.ID)
-		log.Printf("Adding comment to issue %s\n", issue.ID)
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf(msg("invalid_config"), err)
+	}
+	return nil
+}
 
-		// 使用显示名称创建评论
-		// Create comment using display name
-		commentReq := &api.CommentRequest{
-			CommentHTML: commentText,
-			DisplayName: user.DisplayName,
-		}
+// 转换为 goplane 的运行配置
+// Convert to the run configuration of goplane
+func (config Config) pipelineConfig() goplane.Config {
+	run := config.clientConfig()
+	run.Ref = config.ref
+	run.ToState = config.toState
+	run.Comment = config.comment
+	run.Markdown = config.markdown
+	run.IssueLinks = config.issueLinks
+	run.ForgeLinks = config.forgeLinks
+	run.Assignee = config.assignee
+	run.Labels = config.labels
+	run.Module = config.module
+	run.Rules = config.rules
+	run.Defaults = config.defaults
+	return run
+}
 
-		_, err := planeClient.Comments.Create(config.workspaceSlug, issue.Project, issue.ID, commentReq)
-		if err != nil {
-			log.Printf("添加评论失败: %v\n", err)
-			log.Printf("Failed to add comment: %v\n", err)
+// 只包含连接、并发和缓存设置的 goplane 配置, 不执行任何操作
+// goplane configuration with only the connection, concurrency and cache settings, running no operations
+func (config Config) clientConfig() goplane.Config {
+	return goplane.Config{
+		BaseURL:       config.baseURL,
+		Token:         config.token,
+		WorkspaceSlug: config.workspaceSlug,
+		WorkspaceMap:  config.workspaceMap,
+		Workspaces:    config.workspaces,
+		Client:        newClient(config),
+		Concurrency:   config.concurrency,
+		Cache:         config.cache,
+		RefreshCache:  config.refreshCache,
+	}
+}
+
+// 创建缓存: PLANE_CACHE_DIR 默认为用户缓存目录, PLANE_CACHE_TTL 默认为一小时
+// Create the cache: PLANE_CACHE_DIR defaults to the user cache directory, PLANE_CACHE_TTL to one hour
+func loadPlaneCache(values *util.Values) *goplane.Cache {
+	dir := values.String("PLANE_CACHE_DIR", "")
+	if dir == "" {
+		if base, err := os.UserCacheDir(); err == nil {
+			dir = filepath.Join(base, "go-plane")
 		}
 	}
+
+	return goplane.NewCache(dir, values.Duration("PLANE_CACHE_TTL", goplane.DefaultCacheTTL))
 }
@@ -2,7 +2,11 @@ package main
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -37,6 +41,9 @@ func TestLoadConfig(t *testing.T) {
 	os.Setenv("PLANE_DEBUG", "true")
 
 	config := loadConfig()
+	if err := config.settingsErr(); err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
 
 	// 检查配置是否正确
 	// Check if config is correct
@@ -57,19 +64,71 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
-func TestFindIssueBySequenceID(t *testing.T) {
-	// Skip the test in short mode - this is a placeholder for when proper mocking is implemented
-	if testing.Short() {
-		t.Skip("Skipping test in short mode")
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, key := range e2eEnv {
+		t.Setenv(key, "")
+	}
+	t.Setenv("PLANE_CONCURRENCY", "0")
+	t.Setenv("PLANE_CACHE_TTL", "soon")
+	t.Setenv("PLANE_MARKDOWN", "yes please")
+	t.Setenv("PLANE_OUTPUT", "xml")
+	t.Setenv("PLANE_LOG_LEVEL", "verbose")
+	t.Setenv("PLANE_LOG_FORMAT", "yaml")
+	t.Setenv("PLANE_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	config := loadConfig()
+	err := config.settingsErr()
+	if err == nil {
+		t.Fatal("Expected an error for invalid values")
+	}
+	// 所有问题一起报告
+	// All problems are reported together
+	for _, key := range []string{"PLANE_CONCURRENCY", "PLANE_CACHE_TTL", "PLANE_MARKDOWN", "PLANE_OUTPUT", "PLANE_LOG_LEVEL", "PLANE_LOG_FORMAT", "PLANE_TOKEN_FILE"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected the error to mention %s, got:\n%v", key, err)
+		}
+	}
+	if config.concurrency != goplane.DefaultConcurrency || config.output != "text" {
+		t.Errorf("Expected invalid values to fall back to defaults, got %+v", config)
+	}
+
+	// 无效值按当前语言描述
+	// Invalid values are described in the current language
+	defer setLanguage(messages.Language())
+	setLanguage("zh-CN")
+	if got, want := config.settingsErr("PLANE_OUTPUT").Error(), `PLANE_OUTPUT: 无效的值 "xml", 应为 text, json 之一`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
 	}
+}
 
-	// This test would require mocking the Plane client's Issues.GetBySequenceID method
-	// For proper testing, we would need to:
-	// 1. Create a mock Plane client
-	// 2. Mock the GetBySequenceID method to return a predefined issue
-	// 3. Call findIssueBySequenceID with the mock client
-	// 4. Assert that the returned issue matches our expectations
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{name: "valid", config: Config{token: "t", workspaceSlug: "ws", baseURL: "https://plane.example.com/api/v1"}},
+		{name: "missing", config: Config{}, want: []string{"PLANE_TOKEN is required", "PLANE_WORKSPACE_SLUG is required"}},
+		{name: "base url", config: Config{token: "t", workspaceSlug: "ws", baseURL: "plane.example.com"}, want: []string{`invalid URL "plane.example.com"`}},
+	}
 
-	// This is a placeholder for future implementation
-	t.Log("Test for findIssueBySequenceID with GetBySequenceID is a placeholder")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected a validation error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected the error to contain %q, got:\n%v", want, err)
+				}
+			}
+		})
+	}
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"github.com/GeekWorkCode/go-plane/pkg/i18n"
+)
+
+// 各语言的命令消息目录, 叠加在 goplane 的目录之上; 新增语言只需添加 locales/<语言>.json
+// Message catalogs of the command for each language, layered on goplane's; adding a language only requires a new locales/<lang>.json
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// 当前语言的消息目录, 默认为英文
+// Message catalog of the current language, English by default
+var messages = loadMessages(i18n.DefaultLanguage)
+
+// 选择命令和 goplane 的语言
+// Select the language of the command and of goplane
+func setLanguage(lang string) {
+	goplane.SetLanguage(lang)
+	messages = loadMessages(lang)
+}
+
+// 加载最匹配 lang 的消息目录, 缺少的消息从 goplane 的当前目录中查找
+// Load the message catalog best matching lang, looking up missing messages in goplane's current catalog
+func loadMessages(lang string) *i18n.Catalog {
+	locales, err := fs.Sub(localeFiles, "locales")
+	if err != nil {
+		panic(err)
+	}
+
+	// 目录随程序一起编译, 加载失败说明目录文件有误
+	// The catalogs are compiled into the binary, so a failure means a broken catalog file
+	catalog, err := i18n.Load(locales, lang)
+	if err != nil {
+		panic(err)
+	}
+	return catalog.Layer(goplane.Messages())
+}
+
+// 返回当前语言的消息
+// Return the message in the current language
+func msg(id string, args ...any) string {
+	return messages.T(id, args...)
+}
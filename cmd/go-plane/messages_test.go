@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/fs"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"github.com/GeekWorkCode/go-plane/pkg/i18n"
+)
+
+// 匹配格式化动词, 用于比较各语言的参数
+// Match formatting verbs, used to compare the arguments of each language
+var verbRegex = regexp.MustCompile(`%(?:\[\d+\])?[a-zA-Z]`)
+
+func TestMessageCatalogs(t *testing.T) {
+	locales, err := fs.Sub(localeFiles, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	languages, err := i18n.Languages(locales)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(languages, "zh-CN") {
+		t.Errorf("Expected a zh-CN catalog, got %v", languages)
+	}
+
+	english := loadMessages(i18n.DefaultLanguage)
+	for _, lang := range languages {
+		catalog := loadMessages(lang)
+		if catalog.Language() != lang {
+			t.Fatalf("loadMessages(%q) loaded %q", lang, catalog.Language())
+		}
+
+		// 每种语言都必须翻译全部消息, 且参数数量一致
+		// Every language must translate all messages with the same number of arguments
+		for _, id := range english.IDs() {
+			if !catalog.Has(id) {
+				t.Errorf("%s: missing message %q", lang, id)
+				continue
+			}
+			want := len(verbRegex.FindAllString(english.T(id), -1))
+			if got := len(verbRegex.FindAllString(catalog.T(id), -1)); got != want {
+				t.Errorf("%s: message %q has %d arguments, want %d", lang, id, got, want)
+			}
+		}
+		for _, id := range catalog.IDs() {
+			if !english.Has(id) {
+				t.Errorf("%s: unknown message %q", lang, id)
+			}
+		}
+	}
+
+	// 与 goplane 共用的消息只在 goplane 的目录中定义
+	// Messages shared with goplane are only defined in goplane's catalog
+	for _, id := range english.IDs() {
+		if goplane.Messages().Has(id) {
+			t.Errorf("Message %q is already defined by goplane", id)
+		}
+	}
+}
+
+func TestMsg(t *testing.T) {
+	defer setLanguage(messages.Language())
+
+	setLanguage("zh_CN.UTF-8")
+	if got := msg("project_not_found", "PROJ"); got != "未找到项目: PROJ" {
+		t.Errorf("Expected Chinese message from goplane, got %q", got)
+	}
+	if got := msg("invalid_issue_keys", 1, 3); got != "3 个issue key中有 1 个无效" {
+		t.Errorf("Expected reordered arguments, got %q", got)
+	}
+
+	setLanguage("en")
+	if got := msg("project_not_found", "PROJ"); got != "project not found: PROJ" {
+		t.Errorf("Expected English message, got %q", got)
+	}
+	if got := msg("flag_required", "url"); got != "-url is required" {
+		t.Errorf("Expected English command message, got %q", got)
+	}
+}
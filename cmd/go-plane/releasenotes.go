@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+// 默认的发布说明模板
+// Default release notes template
+const defaultReleaseNotesTemplate = `{{range .Groups}}{{if .Issues}}## {{.Title}}
+
+{{range .Issues}}- [{{.Key}}]({{.URL}}) {{.Name}}
+{{end}}
+{{end}}{{end}}`
+
+// 发布说明的分组, 按类型或标签名称中的关键字归类; title 为标题的消息ID
+// Release notes groups, classified by keywords in the type or label names; title is the message ID of the heading
+var releaseNotesGroups = []struct {
+	title    string
+	keywords []string
+}{
+	{title: "release_notes.features", keywords: []string{"feat", "enhancement", "story"}},
+	{title: "release_notes.fixes", keywords: []string{"bug", "fix", "defect", "hotfix"}},
+}
+
+// 发布说明中的issue
+// Issue in the release notes
+type releaseIssue struct {
+	Key    string
+	Name   string
+	State  string
+	Labels []string
+	Type   string
+	URL    string
+}
+
+// 发布说明的一个分组
+// A group of the release notes
+type releaseGroup struct {
+	Title  string
+	Issues []releaseIssue
+}
+
+// 发布说明模板的数据
+// Data of the release notes template
+type releaseNotes struct {
+	Groups []releaseGroup
+	Issues []releaseIssue
+}
+
+func runReleaseNotes(config Config, opts map[string]*string, args []string) error {
+	text, err := releaseNotesSource(config, *opts["range"], args)
+	if err != nil {
+		return err
+	}
+
+	keys := goplane.IssueKeys(text)
+	if len(keys) == 0 {
+		return goplane.ErrNoIssueKeys
+	}
+
+	tmplText := defaultReleaseNotesTemplate
+	if *opts["template"] != "" {
+		data, err := os.ReadFile(*opts["template"])
+		if err != nil {
+			return fmt.Errorf(msg("read_template_failed"), err)
+		}
+		tmplText = string(data)
+	}
+	tmpl, err := template.New("release-notes").Parse(tmplText)
+	if err != nil {
+		return fmt.Errorf(msg("parse_template_failed"), err)
+	}
+
+	notes := collectReleaseNotes(config.clientConfig(), keys)
+
+	var out io.Writer = os.Stdout
+	if *opts["output"] != "" {
+		f, err := os.Create(*opts["output"])
+		if err != nil {
+			return fmt.Errorf(msg("create_output_failed"), err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := tmpl.Execute(out, notes); err != nil {
+		return fmt.Errorf(msg("render_template_failed"), err)
+	}
+	return nil
+}
+
+// 获取要提取issue key的文本: 位置参数 > 提交范围 > 事件负载 > PLANE_REF
+// Get the text to extract issue keys from: positional args > commit range > event payload > PLANE_REF
+func releaseNotesSource(config Config, commitRange string, args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, "\n"), nil
+	}
+
+	if commitRange != "" {
+		out, err := exec.Command("git", "log", "--format=%B", commitRange).Output()
+		if err != nil {
+			return "", fmt.Errorf(msg("read_commit_range_failed"), commitRange, err)
+		}
+		return string(out), nil
+	}
+
+	payload, err := loadEventPayload()
+	if err != nil {
+		return "", err
+	}
+	if payload != nil && len(payload.Commits) > 0 {
+		var messages []string
+		for _, commit := range payload.Commits {
+			messages = append(messages, commit.Message)
+		}
+		return strings.Join(messages, "\n"), nil
+	}
+
+	return config.ref, nil
+}
+
+// 查询每个issue并按分组整理
+// Fetch every issue and organize them into groups
+func collectReleaseNotes(run goplane.Config, keys []string) releaseNotes {
+	states := make(map[string]map[string]string)
+	labels := make(map[string]map[string]string)
+	types := make(map[string]map[string]string)
+
+	var notes releaseNotes
+	for _, key := range keys {
+		issue, run, err := goplane.LocateIssue(run, key)
+		if err != nil {
+			slog.Warn(msg("log.issue_not_found"), "key", key, "err", err)
+			continue
+		}
+
+		if _, ok := states[issue.Project]; !ok {
+			states[issue.Project], labels[issue.Project], types[issue.Project] = projectNames(run, issue.Project)
+		}
+
+		item := releaseIssue{
+			Key:   key,
+			Name:  issue.Name,
+			State: states[issue.Project][issue.State],
+			URL:   goplane.IssueURL(run, issue),
+		}
+
+		if details, err := run.Client.GetIssueDetails(run.WorkspaceSlug, issue.Project, issue.ID); err == nil {
+			item.Type = types[issue.Project][details.TypeID]
+			for _, id := range details.Labels {
+				if name, ok := labels[issue.Project][id]; ok {
+					item.Labels = append(item.Labels, name)
+				}
+			}
+		}
+
+		notes.Issues = append(notes.Issues, item)
+	}
+
+	notes.Groups = groupReleaseIssues(notes.Issues)
+	return notes
+}
+
+// 获取项目中状态、标签和类型的 ID 到名称的映射; 获取失败时记录警告并使用空映射, 不支持issue类型的实例不记录
+// Get the ID to name maps of the states, labels and types of a project; failures are logged as warnings and leave the map empty,
+// except on instances without issue types
+func projectNames(run goplane.Config, projectID string) (map[string]string, map[string]string, map[string]string) {
+	states := make(map[string]string)
+	if list, err := goplane.ListStates(run, projectID); err == nil {
+		for _, state := range list {
+			states[state.ID] = state.Name
+		}
+	} else {
+		slog.Warn(msg("log.list_states_failed"), "project", projectID, "err", err)
+	}
+
+	labels := make(map[string]string)
+	if list, err := goplane.ListLabels(run, projectID); err == nil {
+		for _, label := range list {
+			labels[label.ID] = label.Name
+		}
+	} else {
+		slog.Warn(msg("log.list_labels_failed"), "project", projectID, "err", err)
+	}
+
+	types := make(map[string]string)
+	var statusErr *goplane.StatusError
+	if list, err := goplane.ListIssueTypes(run, projectID); err == nil {
+		for _, t := range list {
+			types[t.ID] = t.Name
+		}
+	} else if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		slog.Warn(msg("log.list_issue_types_failed"), "project", projectID, "err", err)
+	}
+
+	return states, labels, types
+}
+
+// 将issue分组为 Features / Fixes / Other
+// Group issues into Features / Fixes / Other
+func groupReleaseIssues(issues []releaseIssue) []releaseGroup {
+	groups := make([]releaseGroup, len(releaseNotesGroups)+1)
+	for i, g := range releaseNotesGroups {
+		groups[i].Title = msg(g.title)
+	}
+	groups[len(groups)-1].Title = msg("release_notes.other")
+
+	for _, issue := range issues {
+		names := append([]string{issue.Type}, issue.Labels...)
+		index := len(groups) - 1
+		for i, g := range releaseNotesGroups {
+			if matchesKeyword(names, g.keywords) {
+				index = i
+				break
+			}
+		}
+		groups[index].Issues = append(groups[index].Issues, issue)
+	}
+
+	return groups
+}
+
+func matchesKeyword(names, keywords []string) bool {
+	for _, name := range names {
+		name = strings.ToLower(name)
+		for _, keyword := range keywords {
+			if name != "" && strings.Contains(name, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
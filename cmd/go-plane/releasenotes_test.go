@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/GeekWorkCode/go-plane/pkg/planetest"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func TestRenderReleaseNotes(t *testing.T) {
+	issues := []releaseIssue{
+		{Key: "PROJ-1", Name: "Login page", Type: "Feature", URL: "https://plane.example.com/1"},
+		{Key: "PROJ-2", Name: "Crash on logout", Labels: []string{"Bug"}, URL: "https://plane.example.com/2"},
+		{Key: "PROJ-3", Name: "Bump deps", Labels: []string{"chore"}, URL: "https://plane.example.com/3"},
+		{Key: "PROJ-4", Name: "Dark mode", Labels: []string{"enhancement"}, URL: "https://plane.example.com/4"},
+	}
+
+	notes := releaseNotes{Issues: issues, Groups: groupReleaseIssues(issues)}
+	tmpl := template.Must(template.New("release-notes").Parse(defaultReleaseNotesTemplate))
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, notes); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "## Features\n\n" +
+		"- [PROJ-1](https://plane.example.com/1) Login page\n" +
+		"- [PROJ-4](https://plane.example.com/4) Dark mode\n\n" +
+		"## Fixes\n\n" +
+		"- [PROJ-2](https://plane.example.com/2) Crash on logout\n\n" +
+		"## Other\n\n" +
+		"- [PROJ-3](https://plane.example.com/3) Bump deps\n\n"
+	if b.String() != want {
+		t.Errorf("Expected release notes:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestReleaseGroupTitles(t *testing.T) {
+	defer setLanguage(messages.Language())
+	setLanguage("zh-CN")
+
+	var titles []string
+	for _, group := range groupReleaseIssues(nil) {
+		titles = append(titles, group.Title)
+	}
+	if want := []string{"新功能", "问题修复", "其它"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("Expected titles %v, got %v", want, titles)
+	}
+}
+
+func TestCollectReleaseNotes(t *testing.T) {
+	srv := planetest.NewServer()
+	defer srv.Close()
+	srv.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i1", Name: "Login page", Project: "p1", State: "s1"}, SequenceID: 1, TypeID: "t1"},
+			{Issue: models.Issue{ID: "i2", Name: "Crash on logout", Project: "p1", State: "s1"}, SequenceID: 2, Labels: []string{"l1"}},
+		},
+		States:     []models.State{{ID: "s1", Name: "Done", Project: "p1"}},
+		Labels:     []models.Label{{ID: "l1", Name: "bug", Project: "p1"}},
+		IssueTypes: []planetest.IssueType{{ID: "t1", Name: "Feature", Project: "p1"}},
+	})
+
+	run := Config{baseURL: srv.BaseURL(), workspaceSlug: "ws", token: "test-token"}.clientConfig()
+	notes := collectReleaseNotes(run, []string{"PROJ-1", "PROJ-2", "PROJ-9"})
+
+	want := []releaseIssue{
+		{Key: "PROJ-1", Name: "Login page", State: "Done", Type: "Feature", URL: srv.URL + "/ws/projects/p1/issues/i1"},
+		{Key: "PROJ-2", Name: "Crash on logout", State: "Done", Labels: []string{"bug"}, URL: srv.URL + "/ws/projects/p1/issues/i2"},
+	}
+	if !reflect.DeepEqual(notes.Issues, want) {
+		t.Errorf("Expected issues %+v, got %+v", want, notes.Issues)
+	}
+	if groups := notes.Groups; len(groups[0].Issues) != 1 || len(groups[1].Issues) != 1 {
+		t.Errorf("Expected one feature and one fix, got %+v", groups)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+// 输出结果: PLANE_OUTPUT=json 时打印JSON, 在 Actions 中写入 $GITHUB_OUTPUT
+// Write results: print JSON when PLANE_OUTPUT=json, and write $GITHUB_OUTPUT under Actions
+func writeResults(config Config, results *goplane.Result) error {
+	if strings.EqualFold(config.output, "json") {
+		if err := writeResultsJSON(os.Stdout, results); err != nil {
+			return err
+		}
+	}
+
+	if name := os.Getenv("GITHUB_OUTPUT"); name != "" {
+		f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf(msg("open_file_failed"), "GITHUB_OUTPUT", err)
+		}
+		defer f.Close()
+		if err := writeStepOutputs(f, results); err != nil {
+			return fmt.Errorf(msg("write_file_failed"), "GITHUB_OUTPUT", err)
+		}
+	}
+
+	return writeStepSummary(results)
+}
+
+func writeResultsJSON(w io.Writer, results *goplane.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// 写入 GitHub Actions 步骤输出
+// Write GitHub Actions step outputs
+func writeStepOutputs(w io.Writer, results *goplane.Result) error {
+	var keys, urls []string
+	for _, issue := range results.Issues {
+		keys = append(keys, issue.Key)
+		if issue.URL != "" {
+			urls = append(urls, issue.URL)
+		}
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "issue_keys=%s\nissue_urls=%s\nfailed_count=%d\nresults=%s\n",
+		strings.Join(keys, ","), strings.Join(urls, ","), results.FailedCount(), data)
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+func testResults() *goplane.Result {
+	return &goplane.Result{Issues: []*goplane.IssueResult{
+		{
+			Key: "PROJ-1",
+			ID:  "issue-1",
+			URL: "https://plane.example.com/ws/projects/p/issues/issue-1",
+			Actions: []goplane.ActionResult{
+				{Action: "comment", Success: true},
+				{Action: "state", Error: "state not found"},
+			},
+		},
+		{Key: "PROJ-2", Error: "issue not found"},
+		{
+			Key:     "PROJ-3",
+			ID:      "issue-3",
+			URL:     "https://plane.example.com/ws/projects/p/issues/issue-3",
+			Actions: []goplane.ActionResult{{Action: "comment", Success: true}},
+		},
+	}}
+}
+
+func TestWriteStepOutputs(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeStepOutputs(&b, testResults()); err != nil {
+		t.Fatalf("writeStepOutputs() error = %v", err)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	want := []string{
+		"issue_keys=PROJ-1,PROJ-2,PROJ-3",
+		"issue_urls=https://plane.example.com/ws/projects/p/issues/issue-1,https://plane.example.com/ws/projects/p/issues/issue-3",
+		"failed_count=2",
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("Expected line %d to be %q, got %q", i, line, lines[i])
+		}
+	}
+	if !strings.HasPrefix(lines[3], `results={"issues":[`) {
+		t.Errorf("Expected a JSON results output, got %q", lines[3])
+	}
+}
+
+func TestWriteResultsGitHubOutput(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(name, []byte("existing=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_OUTPUT", name)
+
+	if err := writeResults(Config{}, testResults()); err != nil {
+		t.Fatalf("writeResults() error = %v", err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "existing=1\nissue_keys=") {
+		t.Errorf("Expected outputs to be appended, got %q", data)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/util"
+)
+
+const (
+	// 默认最多尝试次数 (包含第一次请求)
+	// Default maximum number of attempts, including the first request
+	defaultRetryAttempts = 4
+
+	// 默认的重试总时限
+	// Default total deadline for retries
+	defaultRetryTimeout = time.Minute
+
+	// 指数退避的初始和最大等待时间
+	// Initial and maximum wait of the exponential backoff
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// 重试策略
+// Retry policy
+type retryPolicy struct {
+	// 最多尝试次数, 1 表示不重试
+	// Maximum number of attempts, 1 disables retries
+	attempts int
+
+	// 从第一次请求开始计算的总时限
+	// Total deadline counted from the first request
+	timeout time.Duration
+}
+
+// 读取重试策略, 无效值使用默认值并记录到 values 中
+// Read the retry policy, falling back to defaults for invalid values, which are recorded in values
+func loadRetryPolicy(values *util.Values) retryPolicy {
+	return retryPolicy{
+		attempts: values.Int("PLANE_RETRY_ATTEMPTS", defaultRetryAttempts, 1),
+		timeout:  values.Duration("PLANE_RETRY_TIMEOUT", defaultRetryTimeout),
+	}
+}
+
+// 带重试的 http.RoundTripper
+// http.RoundTripper with retries
+//
+// 网络错误和 5xx 只对幂等请求重试, 非幂等请求 (如创建评论) 只在请求
+// 确定未被处理时重试: 连接失败或 429.
+// Network errors and 5xx are only retried for idempotent requests; non-idempotent
+// requests (e.g. creating a comment) are only retried when they were certainly not
+// processed: the connection failed or the server answered 429.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy retryPolicy
+
+	// 取消时中止等待中的重试; plane-api-go 的请求不带上下文, 终止信号经由此传递
+	// Cancelling it aborts pending retries; plane-api-go's requests carry no context, so termination signals are passed through it
+	ctx context.Context
+
+	// 等待和计时, 测试中可替换
+	// Waiting and timing, replaceable in tests
+	after func(time.Duration) <-chan time.Time
+	now   func() time.Time
+}
+
+func newRetryTransport(base http.RoundTripper, policy retryPolicy) *retryTransport {
+	return &retryTransport{
+		base:   base,
+		policy: policy,
+		ctx:    context.Background(),
+		after:  time.After,
+		now:    time.Now,
+	}
+}
+
+// 在 ctx 取消时中止默认传输中等待的重试, 须在发出请求前调用
+// Abort the pending retries of the default transport when ctx is cancelled; call it before making requests
+func stopRetriesOn(ctx context.Context) {
+	if t, ok := http.DefaultTransport.(*retryTransport); ok {
+		t.ctx = ctx
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	deadline := t.now().Add(t.policy.timeout)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		delay, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry || attempt >= t.policy.attempts || req.Context().Err() != nil || t.ctx.Err() != nil {
+			return resp, err
+		}
+		if t.now().Add(delay).After(deadline) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		reason := "network error"
+		if err == nil {
+			reason = resp.Status
+		}
+		slog.Warn(msg("log.retrying"),
+			"method", req.Method, "path", req.URL.Path, "reason", reason,
+			"delay", delay, "attempt", attempt, "max_attempts", t.policy.attempts)
+		if err := t.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// 等待 delay, 请求或传输的上下文取消时提前返回其错误
+// Wait for delay, returning early with the error of the request's or the transport's context when it's cancelled
+func (t *retryTransport) wait(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-t.after(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	}
+}
+
+// 判断是否重试以及等待时间
+// Decide whether to retry and how long to wait
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	switch {
+	case err != nil:
+		if !idempotent(req.Method) && !connectFailed(err) {
+			return 0, false
+		}
+		return backoff(attempt), true
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if delay, ok := retryAfter(resp.Header.Get("Retry-After"), t.now()); ok {
+			return delay, true
+		}
+		return backoff(attempt), true
+	case resp.StatusCode >= 500 && idempotent(req.Method):
+		return backoff(attempt), true
+	default:
+		return 0, false
+	}
+}
+
+// 带抖动的指数退避, 等待时间在退避值的一半到全部之间
+// Exponential backoff with jitter, waiting between half and all of the backoff value
+func backoff(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if shift := attempt - 1; shift < 16 {
+		delay = min(retryBaseDelay<<shift, retryMaxDelay)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// 解析 Retry-After 头, 支持秒数和 HTTP 日期
+// Parse the Retry-After header, either seconds or an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// 可以安全重试的请求方法; go-plane 的 PATCH 请求总是设置完整的值, 因此也视为幂等
+// Request methods that are safe to retry; go-plane's PATCH requests always set complete values, so they count as idempotent too
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// 连接未建立, 请求一定没有发送到服务端
+// The connection was never established, so the request certainly never reached the server
+func connectFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/util"
+)
+
+// 按顺序返回预设结果的传输
+// Transport returning preset results in order
+type stubTransport struct {
+	results []func() (*http.Response, error)
+	bodies  []string
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	s.bodies = append(s.bodies, body)
+
+	result := s.results[min(len(s.bodies), len(s.results))-1]
+	return result()
+}
+
+func status(code int, header ...string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: code,
+			Status:     http.StatusText(code),
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			resp.Header.Set(header[i], header[i+1])
+		}
+		return resp, nil
+	}
+}
+
+func failure(err error) func() (*http.Response, error) {
+	return func() (*http.Response, error) { return nil, err }
+}
+
+var (
+	errDial  = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	errReset = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		results    []func() (*http.Response, error)
+		policy     retryPolicy
+		wantCalls  int
+		wantStatus int
+		wantSleep  []time.Duration
+	}{
+		{
+			name:       "success",
+			method:     http.MethodGet,
+			results:    []func() (*http.Response, error){status(200)},
+			wantCalls:  1,
+			wantStatus: 200,
+		},
+		{
+			name:       "get retries 5xx",
+			method:     http.MethodGet,
+			results:    []func() (*http.Response, error){status(502), status(503), status(200)},
+			wantCalls:  3,
+			wantStatus: 200,
+		},
+		{
+			name:       "get retries network error",
+			method:     http.MethodGet,
+			results:    []func() (*http.Response, error){failure(errReset), status(200)},
+			wantCalls:  2,
+			wantStatus: 200,
+		},
+		{
+			name:       "post does not retry 5xx",
+			method:     http.MethodPost,
+			results:    []func() (*http.Response, error){status(500), status(201)},
+			wantCalls:  1,
+			wantStatus: 500,
+		},
+		{
+			name:       "post does not retry reset connection",
+			method:     http.MethodPost,
+			results:    []func() (*http.Response, error){failure(errReset), status(201)},
+			wantCalls:  1,
+			wantStatus: 0,
+		},
+		{
+			name:       "post retries failed dial",
+			method:     http.MethodPost,
+			results:    []func() (*http.Response, error){failure(errDial), status(201)},
+			wantCalls:  2,
+			wantStatus: 201,
+		},
+		{
+			name:       "post retries 429 after Retry-After",
+			method:     http.MethodPost,
+			results:    []func() (*http.Response, error){status(429, "Retry-After", "7"), status(201)},
+			wantCalls:  2,
+			wantStatus: 201,
+			wantSleep:  []time.Duration{7 * time.Second},
+		},
+		{
+			name:       "4xx is not retried",
+			method:     http.MethodGet,
+			results:    []func() (*http.Response, error){status(404), status(200)},
+			wantCalls:  1,
+			wantStatus: 404,
+		},
+		{
+			name:       "max attempts",
+			method:     http.MethodGet,
+			results:    []func() (*http.Response, error){status(503)},
+			policy:     retryPolicy{attempts: 3, timeout: time.Hour},
+			wantCalls:  3,
+			wantStatus: 503,
+		},
+		{
+			name:       "Retry-After beyond deadline",
+			method:     http.MethodGet,
+			results:    []func() (*http.Response, error){status(429, "Retry-After", "120"), status(200)},
+			policy:     retryPolicy{attempts: 4, timeout: time.Minute},
+			wantCalls:  1,
+			wantStatus: 429,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubTransport{results: tt.results}
+			policy := tt.policy
+			if policy.attempts == 0 {
+				policy = retryPolicy{attempts: defaultRetryAttempts, timeout: defaultRetryTimeout}
+			}
+
+			var slept []time.Duration
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			transport := newRetryTransport(stub, policy)
+			transport.now = func() time.Time { return now }
+			transport.after = func(d time.Duration) <-chan time.Time {
+				slept = append(slept, d)
+				now = now.Add(d)
+				ch := make(chan time.Time, 1)
+				ch <- now
+				return ch
+			}
+
+			req, err := http.NewRequest(tt.method, "https://plane.example.com/api/v1/issues/", strings.NewReader(`{"name":"x"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := transport.RoundTrip(req)
+			gotStatus := 0
+			if err == nil {
+				gotStatus = resp.StatusCode
+			}
+
+			if len(stub.bodies) != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, len(stub.bodies))
+			}
+			if gotStatus != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d (err %v)", tt.wantStatus, gotStatus, err)
+			}
+			for i, body := range stub.bodies {
+				if body != `{"name":"x"}` {
+					t.Errorf("Attempt %d sent body %q", i+1, body)
+				}
+			}
+			if tt.wantSleep != nil && !slices.Equal(slept, tt.wantSleep) {
+				t.Errorf("Expected sleeps %v, got %v", tt.wantSleep, slept)
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		// 取消请求的上下文, 否则取消传输的上下文
+		// Cancel the request's context, otherwise the transport's
+		request bool
+	}{
+		{name: "request context", request: true},
+		{name: "transport context"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			stub := &stubTransport{results: []func() (*http.Response, error){status(429, "Retry-After", "30"), status(200)}}
+			transport := newRetryTransport(stub, retryPolicy{attempts: defaultRetryAttempts, timeout: time.Hour})
+
+			req, err := http.NewRequest(http.MethodGet, "https://plane.example.com/api/v1/issues/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.request {
+				req = req.WithContext(ctx)
+			} else {
+				transport.ctx = ctx
+			}
+
+			time.AfterFunc(10*time.Millisecond, cancel)
+			start := time.Now()
+			resp, err := transport.RoundTrip(req)
+
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got response %v and error %v", resp, err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Expected the wait to stop on cancellation, took %v", elapsed)
+			}
+			if len(stub.bodies) != 1 {
+				t.Errorf("Expected 1 call, got %d", len(stub.bodies))
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 20; attempt++ {
+		want := min(retryBaseDelay<<min(attempt-1, 16), retryMaxDelay)
+		for range 10 {
+			if got := backoff(attempt); got < want/2 || got > want {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"Wed, 01 Jan 2025 00:00:10 GMT", 10 * time.Second, true},
+		{"Tue, 31 Dec 2024 23:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := retryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLoadRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    retryPolicy
+		wantErr bool
+	}{
+		{"defaults", nil, retryPolicy{attempts: defaultRetryAttempts, timeout: defaultRetryTimeout}, false},
+		{"custom", map[string]string{"PLANE_RETRY_ATTEMPTS": "1", "PLANE_RETRY_TIMEOUT": "10s"}, retryPolicy{attempts: 1, timeout: 10 * time.Second}, false},
+		{"invalid", map[string]string{"PLANE_RETRY_ATTEMPTS": "-2", "PLANE_RETRY_TIMEOUT": "soon"}, retryPolicy{attempts: defaultRetryAttempts, timeout: defaultRetryTimeout}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := util.NewValues(func(key string) string { return tt.env[key] })
+			got := loadRetryPolicy(values)
+			if got != tt.want {
+				t.Errorf("loadRetryPolicy() = %+v, want %+v", got, tt.want)
+			}
+			if err := values.Err(); (err != nil) != tt.wantErr {
+				t.Errorf("loadRetryPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+	"gopkg.in/yaml.v3"
+)
+
+// 默认的仓库配置文件
+// Default repository configuration files
+var defaultConfigFiles = []string{".go-plane.yml", ".go-plane.yaml"}
+
+// 仓库配置文件 (.go-plane.yml)
+// Repository configuration file (.go-plane.yml)
+type configFile struct {
+	BaseURL       string            `yaml:"base_url"`
+	WorkspaceSlug string            `yaml:"workspace_slug"`
+	WorkspaceMap  map[string]string `yaml:"workspace_map"`
+	Workspaces    []string          `yaml:"workspaces"`
+	ToState       string            `yaml:"to_state"`
+	Comment       string            `yaml:"comment"`
+	Assignee      string            `yaml:"assignee"`
+	Markdown      *bool             `yaml:"markdown"`
+	IssueLinks    string            `yaml:"issue_links"`
+	ForgeLinks    *bool             `yaml:"forge_links"`
+	Debug         *bool             `yaml:"debug"`
+	Rules         []goplane.Rule    `yaml:"rules"`
+}
+
+// 读取仓库配置文件, 未指定路径时查找默认文件, 文件不存在时返回 nil
+// Read the repository configuration file, looking for the default files when no path is given; returns nil if none exists
+func loadConfigFile(name string) (*configFile, error) {
+	candidates := defaultConfigFiles
+	if name != "" {
+		candidates = []string{name}
+	}
+
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if errors.Is(err, os.ErrNotExist) && name == "" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(msg("read_config_failed"), err)
+		}
+
+		var file configFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf(msg("parse_config_failed"), candidate, err)
+		}
+		return &file, nil
+	}
+
+	return nil, nil
+}
+
+// 以环境变量名查找配置文件中的值, 作为环境变量之后的配置来源; file 为 nil 时总是返回空值
+// Look up a value of the configuration file by environment variable name, the configuration source after the environment; always empty when file is nil
+func (file *configFile) lookup(key string) string {
+	if file == nil {
+		return ""
+	}
+
+	switch key {
+	case "PLANE_BASE_URL":
+		return file.BaseURL
+	case "PLANE_WORKSPACE_SLUG":
+		return file.WorkspaceSlug
+	case "PLANE_WORKSPACE_MAP":
+		pairs := make([]string, 0, len(file.WorkspaceMap))
+		for identifier, workspace := range file.WorkspaceMap {
+			pairs = append(pairs, identifier+"="+workspace)
+		}
+		return strings.Join(pairs, ",")
+	case "PLANE_WORKSPACES":
+		return strings.Join(file.Workspaces, ",")
+	case "PLANE_MARKDOWN":
+		return formatOptionalBool(file.Markdown)
+	case "PLANE_ISSUE_LINKS":
+		return file.IssueLinks
+	case "PLANE_FORGE_LINKS":
+		return formatOptionalBool(file.ForgeLinks)
+	case "PLANE_DEBUG":
+		return formatOptionalBool(file.Debug)
+	}
+	return ""
+}
+
+// 配置文件中的操作默认值, 在匹配的规则之后应用, 因此不经由 lookup 合并到环境变量中
+// Operation defaults of the configuration file, applied after the matching rule, so they aren't merged with the environment by lookup
+func (file *configFile) defaults() goplane.Rule {
+	if file == nil {
+		return goplane.Rule{}
+	}
+	return goplane.Rule{State: file.ToState, Comment: file.Comment, Assignee: file.Assignee}
+}
+
+// 未设置的布尔值返回空值
+// Format a boolean, returning empty when it's unset
+func formatOptionalBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+const testConfigFile = `
+workspace_slug: file-workspace
+workspace_map:
+  OPS: ops-workspace
+workspaces: [web-workspace]
+markdown: true
+to_state: Todo
+comment: Linked
+rules:
+  - when:
+      event: push
+      branch: main
+    state: Done
+    labels: [released]
+  - when:
+      event: pull_request
+      action: opened
+    state: In Review
+    comment: "Review requested by {{actor}}"
+  - when:
+      tag: v*
+    module: "{{tag}}"
+`
+
+func TestLoadConfigFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".go-plane.yml")
+	if err := os.WriteFile(name, []byte(testConfigFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := loadConfigFile(name)
+	if err != nil {
+		t.Fatalf("loadConfigFile() error = %v", err)
+	}
+	if len(file.Rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(file.Rules))
+	}
+
+	if file.lookup("PLANE_MARKDOWN") != "true" || file.lookup("PLANE_DEBUG") != "" {
+		t.Errorf("Expected markdown to be set and debug unset, got %q and %q", file.lookup("PLANE_MARKDOWN"), file.lookup("PLANE_DEBUG"))
+	}
+	if (*configFile)(nil).lookup("PLANE_WORKSPACE_SLUG") != "" {
+		t.Error("Expected a missing file to have no values")
+	}
+
+	if _, err := loadConfigFile(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Error("Expected an error for an explicitly configured missing file")
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".go-plane.yml")
+	if err := os.WriteFile(name, []byte(testConfigFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range e2eEnv {
+		t.Setenv(key, "")
+	}
+	t.Setenv("PLANE_CONFIG", name)
+
+	// 环境变量优先于配置文件, INPUT_* 优先于环境变量
+	// Environment variables take precedence over the file, and INPUT_* over the environment
+	t.Setenv("PLANE_WORKSPACE_SLUG", "env-workspace")
+	t.Setenv("PLANE_WORKSPACES", "env-a,env-b")
+	t.Setenv("INPUT_PLANE_WORKSPACES", "input-workspace")
+
+	config := loadConfig()
+	if err := config.settingsErr(); err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.workspaceSlug != "env-workspace" {
+		t.Errorf("Expected workspaceSlug to be %s, got %s", "env-workspace", config.workspaceSlug)
+	}
+	if !reflect.DeepEqual(config.workspaces, []string{"input-workspace"}) {
+		t.Errorf("Expected workspaces to be %v, got %v", []string{"input-workspace"}, config.workspaces)
+	}
+	if !config.markdown {
+		t.Errorf("Expected markdown to be %v, got %v", true, config.markdown)
+	}
+	if config.workspaceMap["OPS"] != "ops-workspace" {
+		t.Errorf("Expected OPS to map to %s, got %v", "ops-workspace", config.workspaceMap)
+	}
+	if len(config.rules) != 3 {
+		t.Errorf("Expected 3 rules, got %d", len(config.rules))
+	}
+	// 配置文件的操作默认值不与环境变量合并, 以免覆盖匹配的规则
+	// Operation defaults of the file aren't merged with the environment, so they don't override the matching rule
+	if config.toState != "" || config.comment != "" {
+		t.Errorf("Expected no state and comment from the file, got %q and %q", config.toState, config.comment)
+	}
+	if want := (goplane.Rule{State: "Todo", Comment: "Linked"}); !reflect.DeepEqual(config.defaults, want) {
+		t.Errorf("Expected defaults %+v, got %+v", want, config.defaults)
+	}
+	// 未设置时使用默认值
+	// Defaults apply when nothing is set
+	if config.concurrency != goplane.DefaultConcurrency || config.retry.attempts != defaultRetryAttempts {
+		t.Errorf("Expected default concurrency and retries, got %d and %d", config.concurrency, config.retry.attempts)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// 日志和调试输出中替换密钥的文本
+// Text replacing secrets in log and debug output
+const redacted = "***"
+
+// 需要从输出中隐藏的密钥, 如 API 令牌
+// Secrets hidden from the output, such as the API token
+var secrets = &redactor{}
+
+// 记录密钥并在文本中替换它们, 并发安全
+// Records secrets and replaces them in text; safe for concurrent use
+type redactor struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+// 登记密钥, 空值或已登记的密钥返回 false
+// Register a secret, returning false for empty or already registered secrets
+func (r *redactor) add(secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.secrets, secret) {
+		return false
+	}
+	r.secrets = append(r.secrets, secret)
+	// 先替换较长的密钥, 避免只替换其中一部分
+	// Replace longer secrets first so none is only partly replaced
+	slices.SortFunc(r.secrets, func(a, b string) int { return len(b) - len(a) })
+	return true
+}
+
+func (r *redactor) redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// 登记密钥, 在 GitHub Actions 中还输出 ::add-mask:: 使运行器在之后的所有输出中隐藏它们
+// Register secrets; under GitHub Actions also write ::add-mask:: so the runner hides them in all later output
+func maskSecrets(w io.Writer, env func(string) string, values ...string) {
+	actions := env("GITHUB_ACTIONS") == "true"
+	for _, value := range values {
+		if !secrets.add(value) || !actions {
+			continue
+		}
+		// 工作流命令只作用于一行, 多行密钥逐行隐藏
+		// A workflow command covers one line, so multi-line secrets are masked line by line
+		for _, line := range strings.Split(value, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(w, "::add-mask::%s\n", escapeWorkflowData(line))
+			}
+		}
+	}
+}
+
+// 在交给内部处理器之前替换消息和字段中密钥的日志处理器
+// Log handler replacing secrets in the message and attributes before passing them to the inner handler
+type redactHandler struct {
+	slog.Handler
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, secrets.redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redactedAttrs[i] = redactAttr(a)
+	}
+	return &redactHandler{Handler: h.Handler.WithAttrs(redactedAttrs)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// 替换字段值中的密钥, 非字符串值按其文本形式处理
+// Replace secrets in an attribute value, treating non-string values by their text form
+func redactAttr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, attr := range group {
+			attrs[i] = redactAttr(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindString, slog.KindAny:
+		if s := value.String(); secrets.redact(s) != s {
+			return slog.String(a.Key, secrets.redact(s))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	r := &redactor{}
+	if r.add("") {
+		t.Error("Expected an empty secret to be ignored")
+	}
+	if !r.add("token") || !r.add("token-long") || r.add("token") {
+		t.Error("Expected each secret to be added once")
+	}
+
+	tests := map[string]string{
+		"":                          "",
+		"no secrets":                "no secrets",
+		"key=token":                 "key=***",
+		"token-long and token":      "*** and ***",
+		"X-API-Key: token-longtext": "X-API-Key: ***text",
+	}
+	for in, want := range tests {
+		if got := r.redact(in); got != want {
+			t.Errorf("redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions string
+		values  []string
+		want    string
+	}{
+		{name: "outside Actions", values: []string{"mask-a"}},
+		{name: "Actions", actions: "true", values: []string{"mask-b", "", "mask-b"}, want: "::add-mask::mask-b\n"},
+		{name: "multi-line", actions: "true", values: []string{"mask-c\n50%\n"}, want: "::add-mask::mask-c\n::add-mask::50%25\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			env := map[string]string{"GITHUB_ACTIONS": tt.actions}
+			maskSecrets(&buf, func(key string) string { return env[key] }, tt.values...)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if got := secrets.redact(tt.values[0]); strings.Contains(got, "mask-") {
+				t.Errorf("Expected %q to be registered, got %q", tt.values[0], got)
+			}
+		})
+	}
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	secrets.add("log-secret")
+
+	tests := []struct {
+		name    string
+		format  string
+		actions bool
+	}{
+		{name: "text"},
+		{name: "json", format: "json"},
+		{name: "actions", actions: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newLogger(&buf, tt.format, tt.actions).With("token", "log-secret")
+			logger.Info("using log-secret", "err", errors.New("bad key log-secret"), slog.Group("req", "header", "log-secret"))
+			logger.Warn("rejected log-secret", "key", "log-secret")
+
+			got := buf.String()
+			if strings.Contains(got, "log-secret") {
+				t.Errorf("Expected the secret to be redacted, got:\n%s", got)
+			}
+			if !strings.Contains(got, redacted) {
+				t.Errorf("Expected %q in the output, got:\n%s", redacted, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+const (
+	// 默认监听地址
+	// Default listen address
+	defaultListenAddr = ":8080"
+
+	// 投递ID的去重保留时间
+	// How long delivery IDs are kept for deduplication
+	deliveryTTL = 24 * time.Hour
+
+	// 请求体的最大长度
+	// Maximum request body size
+	maxWebhookBody = 5 << 20
+
+	// 优雅关闭的最长等待时间
+	// Maximum time to wait for a graceful shutdown
+	shutdownTimeout = 30 * time.Second
+
+	// 同时处理的最大投递数, 超出时返回 503 以便发送方稍后重试
+	// Maximum number of deliveries processed at once; beyond it, 503 is returned so the sender retries later
+	maxConcurrentRuns = 4
+)
+
+// 接收 GitHub/Gitea webhook 的服务
+// Server receiving GitHub/Gitea webhooks
+type webhookServer struct {
+	config Config
+	secret []byte
+
+	// 执行处理流程, 测试中可替换
+	// Runs the pipeline, replaceable in tests
+	run func(context.Context, goplane.Config) (*goplane.Result, error)
+
+	mu sync.Mutex
+	// 成功处理的投递ID及其时间
+	// IDs of successfully handled deliveries and when they were handled
+	deliveries map[string]time.Time
+	// 正在处理的投递ID
+	// IDs of deliveries being processed
+	pending map[string]bool
+	// 处理流程的并发槽位
+	// Concurrency slots for pipeline runs
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newWebhookServer(config Config) *webhookServer {
+	return &webhookServer{
+		config:     config,
+		secret:     []byte(config.webhookSecret),
+		run:        goplane.Run,
+		deliveries: make(map[string]time.Time),
+		pending:    make(map[string]bool),
+		slots:      make(chan struct{}, maxConcurrentRuns),
+	}
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if !verifySignature(s.secret, body, r.Header) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := firstHeader(r.Header, "X-GitHub-Event", "X-Gitea-Event")
+	delivery := firstHeader(r.Header, "X-GitHub-Delivery", "X-Gitea-Delivery")
+	if event == "" {
+		http.Error(w, "missing event header", http.StatusBadRequest)
+		return
+	}
+	if event == "ping" {
+		fmt.Fprintln(w, "pong")
+		return
+	}
+
+	var payload eventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if !s.claim(delivery) {
+		fmt.Fprintln(w, "duplicate delivery")
+		return
+	}
+
+	ref, worklogs := payload.updateRef()
+	if !goplane.IssueKeyPattern.MatchString(ref) {
+		s.finish(delivery, true)
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "no issue keys")
+		return
+	}
+
+	run := s.config.pipelineConfig()
+	run.Ref = ref
+	run.SkipWorklogs = !worklogs
+	run.Event = payload.context(event)
+	run.Author = payload.author()
+
+	slog.Info(msg("log.processing_webhook"), "event", event, "delivery", delivery)
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		s.finish(delivery, false)
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "too many deliveries in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	// 在后台处理, 以便及时响应 webhook 请求
+	// Process in the background so the webhook request is answered promptly
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.slots }()
+		start := time.Now()
+		results, err := s.run(context.Background(), run)
+		logRunError(err)
+		// 部分 issue 失败时同样允许重新投递
+		// Also allow redelivery when some issues failed
+		s.finish(delivery, err == nil && results.FailedCount() == 0)
+		slog.Info(msg("log.delivery_done"), "delivery", delivery, "issues", len(results.Issues),
+			"failed", results.FailedCount(), "duration", time.Since(start))
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintln(w, "accepted")
+}
+
+// 将投递标记为正在处理, 已成功处理或正在处理时返回 false; 没有投递ID时总是返回 true
+// Mark a delivery as being processed, returning false if it was already handled or is being processed; always returns true without a delivery ID
+func (s *webhookServer) claim(delivery string) bool {
+	if delivery == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, at := range s.deliveries {
+		if now.Sub(at) > deliveryTTL {
+			delete(s.deliveries, id)
+		}
+	}
+
+	if _, ok := s.deliveries[delivery]; ok || s.pending[delivery] {
+		return false
+	}
+	s.pending[delivery] = true
+	return true
+}
+
+// 结束投递的处理, 只记录成功的投递, 以便失败的投递可以重新投递
+// Finish processing a delivery, recording it only on success so failed deliveries can be redelivered
+func (s *webhookServer) finish(delivery string, ok bool) {
+	if delivery == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pending, delivery)
+	if ok {
+		s.deliveries[delivery] = time.Now()
+	}
+}
+
+// 等待后台处理完成, 超时返回错误
+// Wait for background processing to finish, returning an error on timeout
+func (s *webhookServer) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 校验 GitHub (X-Hub-Signature-256) 或 Gitea (X-Gitea-Signature) 的 HMAC-SHA256 签名
+// Verify the HMAC-SHA256 signature of GitHub (X-Hub-Signature-256) or Gitea (X-Gitea-Signature)
+func verifySignature(secret, body []byte, header http.Header) bool {
+	signature := header.Get("X-Hub-Signature-256")
+	if signature != "" {
+		var ok bool
+		if signature, ok = strings.CutPrefix(signature, "sha256="); !ok {
+			return false
+		}
+	} else {
+		signature = header.Get("X-Gitea-Signature")
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func runServe(config Config, _ map[string]*string, _ []string) error {
+	if config.webhookSecret == "" {
+		return errors.New(msg("webhook_secret_required"))
+	}
+	if config.listenAddr == "" {
+		config.listenAddr = defaultListenAddr
+	}
+
+	webhooks := newWebhookServer(config)
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", webhooks)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	srv := &http.Server{
+		Addr:              config.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopRetriesOn(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info(msg("log.listening"), "addr", config.listenAddr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf(msg("start_server_failed"), err)
+	case <-ctx.Done():
+	}
+
+	slog.Info(msg("log.shutting_down"))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf(msg("shutdown_server_failed"), err)
+	}
+	return webhooks.wait(shutdownCtx)
+}
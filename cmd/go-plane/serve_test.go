@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+const testPushPayload = `{
+	"ref": "refs/heads/main",
+	"after": "abc123",
+	"repository": {"full_name": "org/repo"},
+	"sender": {"login": "octocat"},
+	"commits": [
+		{"id": "abc123", "message": "PROJ-12 fix login", "author": {"name": "Alice", "email": "alice@example.com"}}
+	],
+	"head_commit": {"id": "abc123", "message": "PROJ-12 fix login", "author": {"name": "Alice", "email": "alice@example.com"}}
+}`
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(testPushPayload)
+	signature := sign("s3cret", testPushPayload)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{name: "github", header: http.Header{"X-Hub-Signature-256": {"sha256=" + signature}}, want: true},
+		{name: "gitea", header: http.Header{"X-Gitea-Signature": {signature}}, want: true},
+		{name: "github without prefix", header: http.Header{"X-Hub-Signature-256": {signature}}, want: false},
+		{name: "wrong signature", header: http.Header{"X-Gitea-Signature": {sign("other", testPushPayload)}}, want: false},
+		{name: "missing signature", header: http.Header{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifySignature(secret, body, tt.header); got != tt.want {
+				t.Errorf("verifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookServer(t *testing.T) {
+	s := newWebhookServer(Config{webhookSecret: "s3cret", toState: "Done"})
+
+	var mu sync.Mutex
+	var calls []goplane.Config
+	s.run = func(_ context.Context, config goplane.Config) (*goplane.Result, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, config)
+		return &goplane.Result{}, nil
+	}
+
+	send := func(event, delivery, body, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("push", "1", testPushPayload, sign("wrong", testPushPayload)); code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a bad signature, got %d", http.StatusUnauthorized, code)
+	}
+	if code := send("ping", "0", "{}", sign("s3cret", "{}")); code != http.StatusOK {
+		t.Errorf("Expected status %d for ping, got %d", http.StatusOK, code)
+	}
+	if code := send("push", "1", testPushPayload, sign("s3cret", testPushPayload)); code != http.StatusAccepted {
+		t.Errorf("Expected status %d, got %d", http.StatusAccepted, code)
+	}
+	// 重复投递被忽略
+	// Duplicate deliveries are ignored
+	if code := send("push", "1", testPushPayload, sign("s3cret", testPushPayload)); code != http.StatusOK {
+		t.Errorf("Expected status %d for a duplicate delivery, got %d", http.StatusOK, code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.wait(ctx); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("Expected the pipeline to run once, got %d", len(calls))
+	}
+	if calls[0].Ref != "PROJ-12 fix login" || calls[0].ToState != "Done" {
+		t.Errorf("Unexpected config ref=%q toState=%q", calls[0].Ref, calls[0].ToState)
+	}
+	want := goplane.EventContext{Event: "push", Branch: "main", SHA: "abc123", Repository: "org/repo", Actor: "octocat"}
+	if calls[0].Event != want {
+		t.Errorf("Expected context %+v, got %+v", want, calls[0].Event)
+	}
+	if calls[0].Author.Email != "alice@example.com" {
+		t.Errorf("Expected author alice@example.com, got %q", calls[0].Author.Email)
+	}
+}
+
+// 发送签名正确的推送投递, 返回状态码
+// Send a correctly signed push delivery, returning the status code
+func deliverPush(s *webhookServer, delivery string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(testPushPayload))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-GitHub-Delivery", delivery)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+sign("s3cret", testPushPayload))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func waitWebhooks(t *testing.T, s *webhookServer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.wait(ctx); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+}
+
+func TestWebhookServerRedelivery(t *testing.T) {
+	s := newWebhookServer(Config{webhookSecret: "s3cret"})
+
+	runs := 0
+	s.run = func(context.Context, goplane.Config) (*goplane.Result, error) {
+		runs++
+		switch runs {
+		case 1:
+			return &goplane.Result{}, errors.New("plane unavailable")
+		case 2:
+			failed := &goplane.Result{}
+			failed.Issues = append(failed.Issues, &goplane.IssueResult{Key: "PROJ-12", Error: "update failed"})
+			return failed, nil
+		}
+		return &goplane.Result{}, nil
+	}
+
+	// 运行失败或 issue 失败的投递可以重新投递, 成功后的重复投递被忽略
+	// A delivery whose run or issues failed can be redelivered; duplicates after a success are ignored
+	wantCodes := []int{http.StatusAccepted, http.StatusAccepted, http.StatusAccepted, http.StatusOK}
+	for i, want := range wantCodes {
+		if code := deliverPush(s, "1"); code != want {
+			t.Errorf("Delivery %d: expected status %d, got %d", i+1, want, code)
+		}
+		waitWebhooks(t, s)
+	}
+	if runs != 3 {
+		t.Errorf("Expected the pipeline to run three times, got %d", runs)
+	}
+}
+
+func TestWebhookServerBusy(t *testing.T) {
+	s := newWebhookServer(Config{webhookSecret: "s3cret"})
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	s.run = func(context.Context, goplane.Config) (*goplane.Result, error) {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+		return &goplane.Result{}, nil
+	}
+
+	for i := range maxConcurrentRuns {
+		if code := deliverPush(s, strconv.Itoa(i)); code != http.StatusAccepted {
+			t.Fatalf("Delivery %d: expected status %d, got %d", i, http.StatusAccepted, code)
+		}
+	}
+	// 并发槽位已满时拒绝, 且不记录投递ID
+	// Deliveries are rejected while all slots are busy, without recording their ID
+	if code := deliverPush(s, "busy"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d when busy, got %d", http.StatusServiceUnavailable, code)
+	}
+	if code := deliverPush(s, "0"); code != http.StatusOK {
+		t.Errorf("Expected status %d for a delivery being processed, got %d", http.StatusOK, code)
+	}
+
+	close(release)
+	waitWebhooks(t, s)
+
+	if code := deliverPush(s, "busy"); code != http.StatusAccepted {
+		t.Errorf("Expected status %d for the redelivery, got %d", http.StatusAccepted, code)
+	}
+	waitWebhooks(t, s)
+	if runs != maxConcurrentRuns+1 {
+		t.Errorf("Expected %d runs, got %d", maxConcurrentRuns+1, runs)
+	}
+}
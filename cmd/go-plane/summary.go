@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+// 作业摘要文件的环境变量, Gitea 运行器可能使用自己的变量名
+// Environment variables of the job summary file; Gitea runners may use their own name
+var stepSummaryEnvs = []string{"GITHUB_STEP_SUMMARY", "GITEA_STEP_SUMMARY"}
+
+// 将处理结果以Markdown表格写入作业摘要
+// Write the results as a Markdown table to the job summary
+func writeStepSummary(results *goplane.Result) error {
+	if len(results.Issues) == 0 {
+		return nil
+	}
+
+	written := make(map[string]bool)
+	for _, env := range stepSummaryEnvs {
+		name := os.Getenv(env)
+		if name == "" || written[name] {
+			continue
+		}
+		written[name] = true
+
+		f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf(msg("open_file_failed"), env, err)
+		}
+		err = renderStepSummary(f, results)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf(msg("write_file_failed"), env, err)
+		}
+	}
+
+	return nil
+}
+
+// 渲染作业摘要
+// Render the job summary
+func renderStepSummary(w io.Writer, results *goplane.Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", msg("summary.title"))
+	fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+		msg("summary.issue"), msg("summary.name"), msg("summary.state"), msg("summary.assignees"), msg("summary.comment"))
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	for _, issue := range results.Issues {
+		key := issue.Key
+		if issue.URL != "" {
+			key = "[" + issue.Key + "](" + issue.URL + ")"
+		}
+
+		title := escapeTableCell(issue.Name)
+		if issue.Error != "" {
+			title = "❌ " + escapeTableCell(issue.Error)
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			key,
+			title,
+			summaryChange(issue, "state", issue.State),
+			summaryChange(issue, "assign", issue.Assignees),
+			summaryAction(issue, "comment"),
+		)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// 摘要中的字段变更: "旧 → 新", 失败时显示错误
+// Field change in the summary: "old → new", or the error on failure
+func summaryChange(issue *goplane.IssueResult, action string, change *goplane.Change) string {
+	if result, ok := issue.Action(action); ok && !result.Success {
+		return "❌ " + escapeTableCell(result.Error)
+	}
+	if change == nil {
+		return "—"
+	}
+
+	from := change.From
+	if from == "" {
+		from = "∅"
+	}
+	return escapeTableCell(from) + " → " + escapeTableCell(change.To)
+}
+
+// 摘要中的操作状态
+// Action status in the summary
+func summaryAction(issue *goplane.IssueResult, action string) string {
+	result, ok := issue.Action(action)
+	switch {
+	case !ok:
+		return "—"
+	case result.Success:
+		return "✅ " + msg("summary.added")
+	default:
+		return "❌ " + escapeTableCell(result.Error)
+	}
+}
+
+// 转义表格单元格中的竖线和换行
+// Escape pipes and newlines in a table cell
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", " ")
+}
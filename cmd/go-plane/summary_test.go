@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/goplane"
+)
+
+func TestRenderStepSummary(t *testing.T) {
+	results := &goplane.Result{Issues: []*goplane.IssueResult{
+		{
+			Key:  "PROJ-1",
+			ID:   "issue-1",
+			Name: "Fix | login",
+			URL:  "https://plane.example.com/1",
+			Actions: []goplane.ActionResult{
+				{Action: "comment", Success: true},
+				{Action: "state", Success: true},
+				{Action: "assign", Success: true},
+			},
+			State:     &goplane.Change{From: "In Progress", To: "Done"},
+			Assignees: &goplane.Change{To: "alice"},
+		},
+		{
+			Key:     "PROJ-2",
+			ID:      "issue-2",
+			Name:    "Crash",
+			Actions: []goplane.ActionResult{{Action: "comment", Error: "forbidden"}},
+		},
+		{Key: "PROJ-3", Error: "not found"},
+	}}
+
+	var b bytes.Buffer
+	if err := renderStepSummary(&b, results); err != nil {
+		t.Fatalf("renderStepSummary() error = %v", err)
+	}
+
+	want := "### Plane issues\n\n" +
+		"| Issue | Title | State | Assignees | Comment |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| [PROJ-1](https://plane.example.com/1) | Fix \\| login | In Progress → Done | ∅ → alice | ✅ added |\n" +
+		"| PROJ-2 | Crash | — | — | ❌ forbidden |\n" +
+		"| PROJ-3 | ❌ not found | — | — | — |\n\n"
+	if b.String() != want {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestWriteStepSummary(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", name)
+	t.Setenv("GITEA_STEP_SUMMARY", name)
+
+	results := &goplane.Result{Issues: []*goplane.IssueResult{{Key: "PROJ-1"}}}
+
+	if err := writeStepSummary(results); err != nil {
+		t.Fatalf("writeStepSummary() error = %v", err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一个文件只写入一次
+	// The same file is only written once
+	if got := strings.Count(string(data), "### Plane issues"); got != 1 {
+		t.Errorf("Expected the summary to be written once, got %d", got)
+	}
+}
@@ -5,6 +5,7 @@ go 1.24.0
 require (
 	github.com/GeekWorkCode/plane-api-go v0.4.0
 	github.com/joho/godotenv v1.5.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 // replace github.com/GeekWorkCode/plane-api-go => ../plane-api-go
@@ -0,0 +1,295 @@
+package goplane
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// DefaultCacheTTL is the usual lifetime of cached lists.
+const DefaultCacheTTL = time.Hour
+
+// Cache keeps the project, state, label, member and issue type lists of Plane
+// workspaces. Each list is fetched at most once per cache, and again when
+// it misses the project, state, label or member looked up; when a directory
+// is set the lists are also written to disk, one file per Plane URL and
+// workspace, so they can be reused between CI runs through actions/cache.
+// A Cache is safe for concurrent use.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	scopes map[string]map[string]cacheEntry
+	locks  map[string]*sync.Mutex
+}
+
+// 缓存的列表, 以JSON保存
+// A cached list, stored as JSON
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewCache returns a cache keeping lists for ttl.
+//
+// Parameters:
+//
+//	dir - directory the lists are stored in, or empty to keep them in memory only.
+//	ttl - how long a list is used before it's fetched again.
+//
+// Returns:
+//
+//	*Cache - the cache.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		dir:    dir,
+		ttl:    ttl,
+		now:    time.Now,
+		scopes: make(map[string]map[string]cacheEntry),
+		locks:  make(map[string]*sync.Mutex),
+	}
+}
+
+// 按 Plane 地址和工作区区分的缓存范围
+// Cache scope, distinguishing Plane URLs and workspaces
+func cacheScope(config Config) string {
+	sum := sha256.Sum256([]byte(config.BaseURL + "\n" + config.WorkspaceSlug))
+	return hex.EncodeToString(sum[:8])
+}
+
+// 返回缓存的列表, 缓存缺失或过期时调用 fetch; 缓存为 nil 时总是调用 fetch.
+// 缓存的列表不满足 found 时重新获取一次, 以便找到缓存之后新建的项目、状态、标签和成员; found 为 nil 时不检查
+// Return the cached list, calling fetch when it's missing or expired; always calls fetch when the cache is nil.
+// A cached list not satisfying found is fetched again once, so projects, states, labels and members created after
+// it was cached are found; found is not checked when nil
+func cached[T any](config Config, key string, fetch func() (T, error), found func(T) bool) (T, error) {
+	c := config.Cache
+	if c == nil {
+		return fetch()
+	}
+
+	scope := cacheScope(config)
+
+	// 同一个列表只允许一个请求, 其余调用等待结果
+	// Only one request per list; concurrent callers wait for its result
+	lock := c.lock(scope + "/" + key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var value T
+	if entry, ok := c.get(scope, key, config.RefreshCache); ok {
+		if err := json.Unmarshal(entry.Data, &value); err == nil && (found == nil || found(value)) {
+			return value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	data, err := json.Marshal(value)
+	if err == nil {
+		c.put(scope, key, cacheEntry{FetchedAt: c.now(), Data: data})
+	}
+	return value, nil
+}
+
+func (c *Cache) lock(key string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[key] = lock
+	}
+	return lock
+}
+
+// 返回未过期的缓存项; 第一次访问某个范围时从磁盘读取, refresh 时忽略磁盘上的缓存
+// Return an unexpired entry; a scope is read from disk on first access, ignoring the stored entries on refresh
+func (c *Cache) get(scope, key string, refresh bool) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, ok := c.scopes[scope]
+	if !ok {
+		entries = make(map[string]cacheEntry)
+		if !refresh {
+			entries = c.read(scope)
+		}
+		c.scopes[scope] = entries
+	}
+
+	entry, ok := entries[key]
+	if !ok || c.now().Sub(entry.FetchedAt) > c.ttl {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// 保存缓存项, 并写入磁盘
+// Store an entry and write it to disk
+func (c *Cache) put(scope, key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.scopes[scope][key] = entry
+	if err := c.write(scope); err != nil {
+		slog.Warn(msg("log.write_cache_failed"), "dir", c.dir, "err", err)
+	}
+}
+
+func (c *Cache) path(scope string) string {
+	return filepath.Join(c.dir, scope+".json")
+}
+
+// 从磁盘读取缓存, 文件不存在或无效时返回空缓存
+// Read the cache from disk, returning an empty cache if the file is missing or invalid
+func (c *Cache) read(scope string) map[string]cacheEntry {
+	entries := make(map[string]cacheEntry)
+	if c.dir == "" {
+		return entries
+	}
+
+	data, err := os.ReadFile(c.path(scope))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(msg("log.read_cache_failed"), "dir", c.dir, "err", err)
+		}
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return make(map[string]cacheEntry)
+	}
+	return entries
+}
+
+// 写入缓存文件, 先写临时文件再重命名, 避免并发运行读到不完整的文件
+// Write the cache file through a temporary file and rename, so concurrent runs never read a partial file
+func (c *Cache) write(scope string) error {
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c.scopes[scope])
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, scope+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(scope))
+}
+
+// 获取工作区的项目列表, 缓存的列表不满足 found 时重新获取
+// List the projects of the workspace, fetching them again when the cached list doesn't satisfy found
+func listProjects(config Config, found func([]models.Project) bool) ([]models.Project, error) {
+	return cached(config, "projects", func() ([]models.Project, error) {
+		return config.Client.ListProjects(config.WorkspaceSlug)
+	}, found)
+}
+
+// 获取项目的状态列表, 缓存的列表不满足 found 时重新获取
+// List the states of a project, fetching them again when the cached list doesn't satisfy found
+func listStates(config Config, projectID string, found func([]models.State) bool) ([]models.State, error) {
+	return cached(config, fmt.Sprintf("states/%s", projectID), func() ([]models.State, error) {
+		return config.Client.ListStates(config.WorkspaceSlug, projectID)
+	}, found)
+}
+
+// 获取项目的标签列表, 缓存的列表不满足 found 时重新获取
+// List the labels of a project, fetching them again when the cached list doesn't satisfy found
+func listLabels(config Config, projectID string, found func([]models.Label) bool) ([]models.Label, error) {
+	return cached(config, fmt.Sprintf("labels/%s", projectID), func() ([]models.Label, error) {
+		return config.Client.ListLabels(config.WorkspaceSlug, projectID)
+	}, found)
+}
+
+// 获取项目的成员列表, 缓存的列表不满足 found 时重新获取
+// List the members of a project, fetching them again when the cached list doesn't satisfy found
+func listMembers(config Config, projectID string, found func([]models.Member) bool) ([]models.Member, error) {
+	return cached(config, fmt.Sprintf("members/%s", projectID), func() ([]models.Member, error) {
+		return config.Client.ListMembers(config.WorkspaceSlug, projectID)
+	}, found)
+}
+
+// ListStates returns the states of a project, from config.Cache when it's
+// set.
+//
+// Parameters:
+//
+//	config - the client, workspace and cache to use.
+//	projectID - the project ID.
+//
+// Returns:
+//
+//	[]models.State - the states of the project.
+//	error - the error of listing the states.
+func ListStates(config Config, projectID string) ([]models.State, error) {
+	config.Client = config.client()
+	return listStates(config, projectID, nil)
+}
+
+// ListLabels returns the labels of a project, from config.Cache when it's
+// set.
+//
+// Parameters:
+//
+//	config - the client, workspace and cache to use.
+//	projectID - the project ID.
+//
+// Returns:
+//
+//	[]models.Label - the labels of the project.
+//	error - the error of listing the labels.
+func ListLabels(config Config, projectID string) ([]models.Label, error) {
+	config.Client = config.client()
+	return listLabels(config, projectID, nil)
+}
+
+// ListIssueTypes returns the issue types of a project, from config.Cache
+// when it's set.
+//
+// Parameters:
+//
+//	config - the client, workspace and cache to use.
+//	projectID - the project ID.
+//
+// Returns:
+//
+//	[]IssueType - the issue types of the project.
+//	error - the error of listing the issue types, a *StatusError with 404
+//	when the instance doesn't support them.
+func ListIssueTypes(config Config, projectID string) ([]IssueType, error) {
+	config.Client = config.client()
+	return cached(config, fmt.Sprintf("issue-types/%s", projectID), func() ([]IssueType, error) {
+		return config.Client.ListIssueTypes(config.WorkspaceSlug, projectID)
+	}, nil)
+}
@@ -0,0 +1,128 @@
+package goplane
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func TestCachedOncePerRun(t *testing.T) {
+	config := Config{BaseURL: "https://plane.example.com/api/v1", WorkspaceSlug: "ws", Cache: NewCache("", time.Hour)}
+
+	var calls atomic.Int32
+	fetch := func() ([]models.Project, error) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond)
+		return []models.Project{{ID: "p1", Identifier: "PROJ"}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			projects, err := cached(config, "projects", fetch, nil)
+			if err != nil || len(projects) != 1 || projects[0].Identifier != "PROJ" {
+				t.Errorf("cached() = %v, %v", projects, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 fetch, got %d", calls.Load())
+	}
+}
+
+func TestCachedErrorsAreNotCached(t *testing.T) {
+	config := Config{WorkspaceSlug: "ws", Cache: NewCache("", time.Hour)}
+
+	calls := 0
+	fetch := func() ([]models.State, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return []models.State{{ID: "s1", Name: "Done"}}, nil
+	}
+
+	if _, err := cached(config, "states/p1", fetch, nil); err == nil {
+		t.Fatal("Expected the first call to fail")
+	}
+	states, err := cached(config, "states/p1", fetch, nil)
+	if err != nil || len(states) != 1 {
+		t.Errorf("cached() = %v, %v", states, err)
+	}
+}
+
+func TestCachedOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newCache := func() *Cache {
+		c := NewCache(dir, time.Hour)
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	calls := 0
+	fetch := func() ([]models.Label, error) {
+		calls++
+		return []models.Label{{ID: "l1", Name: "bug"}}, nil
+	}
+	config := Config{BaseURL: "https://plane.example.com/api/v1", WorkspaceSlug: "ws"}
+
+	tests := []struct {
+		name      string
+		config    func(Config) Config
+		advance   time.Duration
+		wantCalls int
+	}{
+		{name: "first run fetches", wantCalls: 1},
+		{name: "next run reads disk", wantCalls: 1},
+		{name: "refresh fetches", config: func(c Config) Config { c.RefreshCache = true; return c }, wantCalls: 2},
+		{name: "other workspace fetches", config: func(c Config) Config { c.WorkspaceSlug = "other"; return c }, wantCalls: 3},
+		{name: "fresh entry is reused", advance: 30 * time.Minute, wantCalls: 3},
+		{name: "expired entry fetches", advance: 2 * time.Hour, wantCalls: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			c := config
+			if tt.config != nil {
+				c = tt.config(c)
+			}
+			c.Cache = newCache()
+
+			labels, err := cached(c, "labels/p1", fetch, nil)
+			if err != nil || len(labels) != 1 || labels[0].Name != "bug" {
+				t.Errorf("cached() = %v, %v", labels, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d fetches, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestCachedWithoutCache(t *testing.T) {
+	calls := 0
+	fetch := func() ([]models.Member, error) {
+		calls++
+		return nil, nil
+	}
+
+	for range 2 {
+		if _, err := cached(Config{}, "members/p1", fetch, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 fetches without a cache, got %d", calls)
+	}
+}
@@ -0,0 +1,197 @@
+package goplane
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	plane "github.com/GeekWorkCode/plane-api-go"
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/client"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// Client is the subset of the Plane API used by Run. NewClient returns the
+// implementation backed by plane-api-go; tests and embedding programs can
+// provide their own. CreateWorklog should return a *StatusError with 404
+// or 405 when the instance doesn't support worklogs, so Run records the
+// time as a comment instead; likewise ListIssueTypes should return one with
+// 404 when the instance doesn't support issue types.
+type Client interface {
+	ListProjects(workspaceSlug string) ([]models.Project, error)
+	ListStates(workspaceSlug, projectID string) ([]models.State, error)
+	ListLabels(workspaceSlug, projectID string) ([]models.Label, error)
+	ListMembers(workspaceSlug, projectID string) ([]models.Member, error)
+	ListModules(workspaceSlug, projectID string) ([]models.Module, error)
+	ListIssueTypes(workspaceSlug, projectID string) ([]IssueType, error)
+
+	// GetIssueBySequenceID gets an issue by its key, e.g. "PROJ-12".
+	GetIssueBySequenceID(workspaceSlug, key string) (*models.Issue, error)
+	GetIssueDetails(workspaceSlug, projectID, issueID string) (*IssueDetails, error)
+	UpdateIssue(workspaceSlug, projectID, issueID string, req *api.IssueUpdateRequest) (*models.Issue, error)
+	SetIssueLabels(workspaceSlug, projectID, issueID string, labelIDs []string) error
+
+	CreateComment(workspaceSlug, projectID, issueID string, req *api.CommentRequest) (*models.Comment, error)
+	CreateWorklog(workspaceSlug, projectID, issueID string, req *api.WorklogCreateRequest) (*models.Worklog, error)
+	CreateModule(workspaceSlug, projectID string, req *api.ModuleCreateRequest) (*models.Module, error)
+	AddModuleIssues(workspaceSlug, projectID, moduleID string, issueIDs []string) error
+}
+
+// IssueDetails holds the issue fields missing from plane-api-go's
+// models.Issue.
+type IssueDetails struct {
+	Labels     []string   `json:"labels"`
+	TypeID     string     `json:"type_id"`
+	ArchivedAt *time.Time `json:"archived_at"`
+}
+
+// IssueType is an issue type, missing from plane-api-go; only instances
+// with issue types enabled serve them.
+type IssueType struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Project string `json:"project"`
+}
+
+// plane-api-go 实现的 Client, 未封装的请求使用底层客户端
+// Client implemented with plane-api-go, using the low level client for requests it doesn't wrap
+type planeClient struct {
+	plane *plane.Plane
+	raw   *client.Client
+}
+
+// NewClient returns a Client backed by plane-api-go. Requests go through
+// http.DefaultTransport, which can be wrapped to log or retry them; the
+// debug mode of plane-api-go isn't used, as it prints the token to stdout.
+//
+// Parameters:
+//
+//	baseURL - the Plane API base URL, or empty for Plane Cloud.
+//	token - the Plane API token.
+//
+// Returns:
+//
+//	Client - the Plane client.
+func NewClient(baseURL, token string) Client {
+	c := &planeClient{
+		plane: plane.NewClient(token),
+		raw:   client.NewClient(token),
+	}
+	if baseURL != "" {
+		c.plane.SetBaseURL(baseURL)
+		c.raw.SetBaseURL(baseURL)
+	}
+	return c
+}
+
+func (c *planeClient) ListProjects(workspaceSlug string) ([]models.Project, error) {
+	return c.plane.Projects.List(workspaceSlug)
+}
+
+func (c *planeClient) ListStates(workspaceSlug, projectID string) ([]models.State, error) {
+	return c.plane.States.List(workspaceSlug, projectID)
+}
+
+func (c *planeClient) ListLabels(workspaceSlug, projectID string) ([]models.Label, error) {
+	return c.plane.Labels.List(workspaceSlug, projectID)
+}
+
+func (c *planeClient) ListMembers(workspaceSlug, projectID string) ([]models.Member, error) {
+	return c.plane.Members.List(workspaceSlug, projectID)
+}
+
+func (c *planeClient) ListModules(workspaceSlug, projectID string) ([]models.Module, error) {
+	return c.plane.Modules.List(workspaceSlug, projectID)
+}
+
+// 通过底层客户端获取issue类型列表, 以便返回带状态码的 StatusError
+// List the issue types through the low level client, so failures return a StatusError with the status code
+func (c *planeClient) ListIssueTypes(workspaceSlug, projectID string) ([]IssueType, error) {
+	path := fmt.Sprintf("/workspaces/%s/projects/%s/issue-types/", workspaceSlug, projectID)
+	req, err := c.raw.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []IssueType
+	resp, err := c.raw.Do(req, &types)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			return nil, &StatusError{StatusCode: resp.StatusCode, Err: err}
+		}
+		return nil, err
+	}
+	return types, nil
+}
+
+func (c *planeClient) GetIssueBySequenceID(workspaceSlug, key string) (*models.Issue, error) {
+	return c.plane.Issues.GetBySequenceID(workspaceSlug, key)
+}
+
+// 通过底层客户端获取issue的完整字段
+// Fetch the full fields of an issue through the low level client
+func (c *planeClient) GetIssueDetails(workspaceSlug, projectID, issueID string) (*IssueDetails, error) {
+	path := fmt.Sprintf("/workspaces/%s/projects/%s/issues/%s/", workspaceSlug, projectID, issueID)
+	req, err := c.raw.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var details IssueDetails
+	if _, err := c.raw.Do(req, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+func (c *planeClient) UpdateIssue(workspaceSlug, projectID, issueID string, req *api.IssueUpdateRequest) (*models.Issue, error) {
+	return c.plane.Issues.Update(workspaceSlug, projectID, issueID, req)
+}
+
+// 设置issue的标签, plane-api-go 的 IssueUpdateRequest 未包含该字段
+// Set the labels of an issue, a field missing from plane-api-go's IssueUpdateRequest
+func (c *planeClient) SetIssueLabels(workspaceSlug, projectID, issueID string, labelIDs []string) error {
+	path := fmt.Sprintf("/workspaces/%s/projects/%s/issues/%s/", workspaceSlug, projectID, issueID)
+	body := struct {
+		Labels []string `json:"labels"`
+	}{Labels: labelIDs}
+
+	req, err := c.raw.NewRequest(http.MethodPatch, path, &body)
+	if err != nil {
+		return err
+	}
+	_, err = c.raw.Do(req, nil)
+	return err
+}
+
+func (c *planeClient) CreateComment(workspaceSlug, projectID, issueID string, req *api.CommentRequest) (*models.Comment, error) {
+	return c.plane.Comments.Create(workspaceSlug, projectID, issueID, req)
+}
+
+// 通过底层客户端记录工时, 以便返回带状态码的 StatusError
+// Create a worklog through the low level client, so failures return a StatusError with the status code
+func (c *planeClient) CreateWorklog(workspaceSlug, projectID, issueID string, req *api.WorklogCreateRequest) (*models.Worklog, error) {
+	path := fmt.Sprintf("/workspaces/%s/projects/%s/issues/%s/worklogs/", workspaceSlug, projectID, issueID)
+	httpReq, err := c.raw.NewRequest(http.MethodPost, path, req)
+	if err != nil {
+		return nil, err
+	}
+
+	worklog := new(models.Worklog)
+	resp, err := c.raw.Do(httpReq, worklog)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			return nil, &StatusError{StatusCode: resp.StatusCode, Err: err}
+		}
+		return nil, err
+	}
+	return worklog, nil
+}
+
+func (c *planeClient) CreateModule(workspaceSlug, projectID string, req *api.ModuleCreateRequest) (*models.Module, error) {
+	return c.plane.Modules.Create(workspaceSlug, projectID, req)
+}
+
+func (c *planeClient) AddModuleIssues(workspaceSlug, projectID, moduleID string, issueIDs []string) error {
+	return c.plane.Modules.AddIssues(workspaceSlug, projectID, moduleID, issueIDs)
+}
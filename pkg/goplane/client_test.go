@@ -0,0 +1,348 @@
+package goplane
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/planetest"
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func TestPlaneClient(t *testing.T) {
+	server := planetest.NewServer()
+	defer server.Close()
+	server.Token = "token"
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i1", Name: "Login page", Project: "p1", State: "s1"}, SequenceID: 1, Labels: []string{"l1"}},
+		},
+		States:  []models.State{{ID: "s1", Name: "Todo", Project: "p1"}, {ID: "s2", Name: "Done", Project: "p1"}},
+		Labels:  []models.Label{{ID: "l1", Name: "bug", Project: "p1"}, {ID: "l2", Name: "released", Project: "p1"}},
+		Members: []models.MemberUser{{ID: "u1", DisplayName: "alice"}},
+	})
+
+	config := Config{
+		BaseURL:       server.BaseURL(),
+		WorkspaceSlug: "ws",
+		Client:        NewClient(server.BaseURL(), "token"),
+		Ref:           "PROJ-1 fix login #time 30m",
+		Comment:       "**Fixed**",
+		Markdown:      true,
+		ToState:       "Done",
+		Assignee:      "alice",
+		Labels:        []string{"released"},
+		Module:        "v1.0",
+	}
+
+	results, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if results.FailedCount() != 0 {
+		t.Errorf("Expected no failures, got %+v", results.Issues)
+	}
+
+	issue, _ := server.Issue("ws", "i1")
+	if issue.State != "s2" {
+		t.Errorf("Expected state s2, got %s", issue.State)
+	}
+	if !reflect.DeepEqual(issue.Assignees, []string{"u1"}) {
+		t.Errorf("Expected assignees [u1], got %v", issue.Assignees)
+	}
+	if !reflect.DeepEqual(issue.Labels, []string{"l1", "l2"}) {
+		t.Errorf("Expected labels [l1 l2], got %v", issue.Labels)
+	}
+	if comments := server.Comments("ws", "i1"); len(comments) != 1 || comments[0].CommentHTML != "<strong>Fixed</strong>" {
+		t.Errorf("Unexpected comments %+v", comments)
+	}
+	if worklogs := server.Worklogs("ws", "i1"); len(worklogs) != 1 || worklogs[0].Duration != 30 {
+		t.Errorf("Unexpected worklogs %+v", worklogs)
+	}
+	modules := server.Modules("ws", "p1")
+	if len(modules) != 1 || modules[0].Name != "v1.0" {
+		t.Fatalf("Expected module v1.0 to be created, got %+v", modules)
+	}
+	if got := server.ModuleIssues("ws", modules[0].ID); !reflect.DeepEqual(got, []string{"i1"}) {
+		t.Errorf("Expected module issues [i1], got %v", got)
+	}
+
+	details, err := config.Client.GetIssueDetails("ws", "p1", "i1")
+	if err != nil || !reflect.DeepEqual(details.Labels, []string{"l1", "l2"}) {
+		t.Errorf("GetIssueDetails() = %+v, %v", details, err)
+	}
+
+	var statusErr *StatusError
+	_, err = config.Client.CreateWorklog("ws", "missing", "i1", &api.WorklogCreateRequest{Duration: 5})
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected CreateWorklog() to return a 404 StatusError, got %v", err)
+	}
+	if _, err = config.Client.ListIssueTypes("ws", "missing"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected ListIssueTypes() to return a 404 StatusError, got %v", err)
+	}
+}
+
+// 启动包含三个工作区的假 Plane: ws-a 中的 PROJ, ws-b 中序列号相同的 OPS 和 WEB, 以及空的 ws-c
+// Start a fake Plane with three workspaces: PROJ in ws-a, OPS and WEB with equal sequence numbers in ws-b, and an empty ws-c
+func newWorkspacesServer(t *testing.T) *planetest.Server {
+	t.Helper()
+
+	server := planetest.NewServer()
+	t.Cleanup(server.Close)
+	server.Seed("ws-a", planetest.Fixtures{
+		Projects: []models.Project{{ID: "pa", Identifier: "PROJ"}},
+		Issues:   []planetest.Issue{{Issue: models.Issue{ID: "ia", Project: "pa"}, SequenceID: 1}},
+		States:   []models.State{{ID: "sa", Name: "Done", Project: "pa"}},
+	})
+	server.Seed("ws-b", planetest.Fixtures{
+		Projects: []models.Project{{ID: "pb", Identifier: "OPS"}, {ID: "pw", Identifier: "WEB"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "ib", Project: "pb"}, SequenceID: 1},
+			{Issue: models.Issue{ID: "iw", Project: "pw"}, SequenceID: 1},
+		},
+		States: []models.State{{ID: "sb", Name: "Done", Project: "pb"}, {ID: "sw", Name: "Done", Project: "pw"}},
+	})
+	server.Seed("ws-c", planetest.Fixtures{})
+	return server
+}
+
+func TestProjectConfig(t *testing.T) {
+	server := newWorkspacesServer(t)
+
+	tests := []struct {
+		name          string
+		workspaceSlug string
+		workspaceMap  map[string]string
+		workspaces    []string
+		identifier    string
+		want          string
+		wantErr       bool
+	}{
+		{name: "default workspace", workspaceSlug: "ws-a", identifier: "PROJ", want: "ws-a"},
+		{name: "mapped", workspaceSlug: "ws-a", workspaceMap: map[string]string{"OPS": "ws-b"}, identifier: "ops", want: "ws-b"},
+		{name: "mapped only", workspaceSlug: "ws-a", workspaceMap: map[string]string{"PROJ": "ws-c"}, identifier: "PROJ", wantErr: true},
+		{name: "discovered", workspaceSlug: "ws-c", workspaces: []string{"ws-a", "ws-b"}, identifier: "OPS", want: "ws-b"},
+		{name: "discovered second project", workspaceSlug: "ws-c", workspaces: []string{"ws-a", "ws-b"}, identifier: "WEB", want: "ws-b"},
+		{name: "unknown workspace skipped", workspaces: []string{"nope", "ws-b"}, identifier: "OPS", want: "ws-b"},
+		{name: "not found", workspaceSlug: "ws-a", workspaces: []string{"ws-b"}, identifier: "APP", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				BaseURL:       server.BaseURL(),
+				WorkspaceSlug: tt.workspaceSlug,
+				WorkspaceMap:  tt.workspaceMap,
+				Workspaces:    tt.workspaces,
+			}
+
+			routed, project, err := ProjectConfig(config, tt.identifier)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got workspace %s", routed.WorkspaceSlug)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProjectConfig() error = %v", err)
+			}
+			if routed.WorkspaceSlug != tt.want || !strings.EqualFold(project.Identifier, tt.identifier) {
+				t.Errorf("ProjectConfig() = %s, %+v, want workspace %s", routed.WorkspaceSlug, project, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWorkspaces(t *testing.T) {
+	server := newWorkspacesServer(t)
+	config := Config{
+		BaseURL:       server.BaseURL(),
+		WorkspaceSlug: "ws-a",
+		WorkspaceMap:  map[string]string{"OPS": "ws-b"},
+		Workspaces:    []string{"ws-b"},
+		Ref:           "PROJ-1 OPS-1 WEB-1 release #time 1h",
+		ToState:       "Done",
+	}
+
+	results, err := Run(context.Background(), config)
+	if err != nil || results.FailedCount() != 0 {
+		t.Fatalf("Run() = %+v, %v", results.Issues, err)
+	}
+
+	for _, tt := range []struct{ workspace, issueID, state string }{
+		{"ws-a", "ia", "sa"},
+		{"ws-b", "ib", "sb"},
+		{"ws-b", "iw", "sw"},
+	} {
+		issue, _ := server.Issue(tt.workspace, tt.issueID)
+		if issue.State != tt.state {
+			t.Errorf("Expected %s to be in state %s, got %s", tt.issueID, tt.state, issue.State)
+		}
+		if worklogs := server.Worklogs(tt.workspace, tt.issueID); len(worklogs) != 1 {
+			t.Errorf("Expected a worklog on %s, got %+v", tt.issueID, worklogs)
+		}
+	}
+	if !strings.HasSuffix(results.Issues[1].URL, "/ws-b/projects/pb/issues/ib") {
+		t.Errorf("Expected the URL of OPS-1 to use ws-b, got %s", results.Issues[1].URL)
+	}
+	if !strings.HasSuffix(results.Issues[2].URL, "/ws-b/projects/pw/issues/iw") {
+		t.Errorf("Expected WEB-1 to resolve to its own issue in ws-b, got %s", results.Issues[2].URL)
+	}
+}
+
+func TestRunSharedSequenceIDs(t *testing.T) {
+	server := planetest.NewServer()
+	defer server.Close()
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}, {ID: "p2", Identifier: "OPS"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i1", Project: "p1"}, SequenceID: 1},
+			{Issue: models.Issue{ID: "i2", Project: "p2"}, SequenceID: 1},
+		},
+		States: []models.State{{ID: "s1", Name: "Done", Project: "p1"}, {ID: "s2", Name: "Done", Project: "p2"}},
+	})
+
+	config := Config{BaseURL: server.BaseURL(), WorkspaceSlug: "ws", Ref: "PROJ-1 OPS-1", ToState: "Done"}
+	results, err := Run(context.Background(), config)
+	if err != nil || results.FailedCount() != 0 {
+		t.Fatalf("Run() = %+v, %v", results.Issues, err)
+	}
+
+	for i, want := range []struct{ id, state string }{{"i1", "s1"}, {"i2", "s2"}} {
+		if got := results.Issues[i]; got.ID != want.id || len(got.Actions) == 0 {
+			t.Errorf("Expected %s to resolve to %s with actions, got %+v", got.Key, want.id, got)
+		}
+		if issue, _ := server.Issue("ws", want.id); issue.State != want.state {
+			t.Errorf("Expected %s to be in state %s, got %s", want.id, want.state, issue.State)
+		}
+	}
+
+	updates := map[string]int{}
+	for _, req := range server.Writes() {
+		updates[req.Path]++
+	}
+	for path, n := range updates {
+		if n > 1 {
+			t.Errorf("Expected one write to %s, got %d", path, n)
+		}
+	}
+}
+
+func TestRunRefetchesMissing(t *testing.T) {
+	server := planetest.NewServer()
+	defer server.Close()
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}},
+		Issues:   []planetest.Issue{{Issue: models.Issue{ID: "i1", Project: "p1"}, SequenceID: 1}},
+		States:   []models.State{{ID: "s1", Name: "Todo", Project: "p1"}},
+		Labels:   []models.Label{{ID: "l1", Name: "bug", Project: "p1"}},
+		Members:  []models.MemberUser{{ID: "u1", DisplayName: "alice"}},
+	})
+
+	config := Config{BaseURL: server.BaseURL(), WorkspaceSlug: "ws", Cache: NewCache("", time.Hour)}
+	first := config
+	first.Ref, first.ToState, first.Labels, first.Assignee = "PROJ-1", "Todo", []string{"bug"}, "alice"
+	if results, err := Run(context.Background(), first); err != nil || results.FailedCount() != 0 {
+		t.Fatalf("Run() = %+v, %v", results.Issues, err)
+	}
+
+	// 缓存之后新建的项目、状态、标签和成员在列表中找不到时重新获取
+	// Projects, states, labels and members created after the lists were cached are fetched again when missing
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p2", Identifier: "OPS"}},
+		Issues:   []planetest.Issue{{Issue: models.Issue{ID: "i2", Project: "p2"}, SequenceID: 1}},
+		States:   []models.State{{ID: "s2", Name: "Done", Project: "p1"}, {ID: "s3", Name: "Done", Project: "p2"}},
+		Labels:   []models.Label{{ID: "l2", Name: "released", Project: "p1"}, {ID: "l3", Name: "released", Project: "p2"}},
+		Members:  []models.MemberUser{{ID: "u2", DisplayName: "bob"}},
+	})
+
+	second := config
+	second.Ref, second.ToState, second.Labels, second.Assignee = "PROJ-1 OPS-1", "Done", []string{"released"}, "bob"
+	if results, err := Run(context.Background(), second); err != nil || results.FailedCount() != 0 {
+		t.Fatalf("Run() = %+v, %v", results.Issues, err)
+	}
+
+	for _, want := range []struct{ id, state string }{{"i1", "s2"}, {"i2", "s3"}} {
+		if issue, _ := server.Issue("ws", want.id); issue.State != want.state || !reflect.DeepEqual(issue.Assignees, []string{"u2"}) {
+			t.Errorf("Expected %s to be in state %s and assigned to u2, got %+v", want.id, want.state, issue)
+		}
+	}
+}
+
+func TestRunListsOnce(t *testing.T) {
+	server := planetest.NewServer()
+	defer server.Close()
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}, {ID: "p2", Identifier: "OPS"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i1", Project: "p1"}, SequenceID: 1},
+			{Issue: models.Issue{ID: "i2", Project: "p1"}, SequenceID: 2},
+			{Issue: models.Issue{ID: "i3", Project: "p1"}, SequenceID: 3},
+			{Issue: models.Issue{ID: "i4", Project: "p2"}, SequenceID: 1},
+		},
+		States:  []models.State{{ID: "s1", Name: "Done", Project: "p1"}, {ID: "s2", Name: "Done", Project: "p2"}},
+		Members: []models.MemberUser{{ID: "u1", DisplayName: "alice"}},
+	})
+
+	// 不使用缓存, 每个项目的状态和成员列表也只获取一次
+	// Without a cache, the state and member lists are still fetched once per project
+	config := Config{BaseURL: server.BaseURL(), WorkspaceSlug: "ws", Ref: "PROJ-1 PROJ-2 PROJ-3 OPS-1", ToState: "Done", Assignee: "alice"}
+	results, err := Run(context.Background(), config)
+	if err != nil || results.FailedCount() != 0 {
+		t.Fatalf("Run() = %+v, %v", results.Issues, err)
+	}
+
+	lists := map[string]int{}
+	for _, req := range server.Requests() {
+		path := strings.TrimSuffix(req.Path, "/")
+		if strings.HasSuffix(path, "/states") || strings.HasSuffix(path, "/members") {
+			lists[path]++
+		}
+	}
+	if len(lists) != 4 {
+		t.Errorf("Expected state and member lists of 2 projects, got %v", lists)
+	}
+	for path, n := range lists {
+		if n != 1 {
+			t.Errorf("Expected %s to be fetched once, got %d", path, n)
+		}
+	}
+
+	for _, id := range []string{"i1", "i2", "i3", "i4"} {
+		if issue, _ := server.Issue("ws", id); len(issue.Assignees) != 1 || issue.Assignees[0] != "u1" {
+			t.Errorf("Expected %s to be assigned to u1, got %v", id, issue.Assignees)
+		}
+	}
+}
+
+func TestListCached(t *testing.T) {
+	server := planetest.NewServer()
+	defer server.Close()
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}},
+		States:   []models.State{{ID: "s1", Name: "Done", Project: "p1"}},
+		Labels:   []models.Label{{ID: "l1", Name: "bug", Project: "p1"}},
+	})
+
+	config := Config{BaseURL: server.BaseURL(), WorkspaceSlug: "ws", Cache: NewCache("", time.Hour)}
+	for range 2 {
+		states, err := ListStates(config, "p1")
+		if err != nil || len(states) != 1 || states[0].Name != "Done" {
+			t.Fatalf("ListStates() = %+v, %v", states, err)
+		}
+		labels, err := ListLabels(config, "p1")
+		if err != nil || len(labels) != 1 || labels[0].Name != "bug" {
+			t.Fatalf("ListLabels() = %+v, %v", labels, err)
+		}
+	}
+
+	if got := len(server.Requests()); got != 2 {
+		t.Errorf("Expected the states and labels to be fetched once, got %d requests", got)
+	}
+}
@@ -0,0 +1,65 @@
+package goplane
+
+import "fmt"
+
+var (
+	// ErrNoIssueKeys is returned by Run when Config.Ref doesn't reference
+	// any issue key.
+	ErrNoIssueKeys error = &messageError{id: "no_issue_keys_found"}
+
+	// ErrNoIssues is returned by Run when none of the referenced issues
+	// was found.
+	ErrNoIssues error = &messageError{id: "no_issues_found"}
+)
+
+// 以当前语言显示的错误
+// Error shown in the current language
+type messageError struct {
+	id string
+}
+
+func (e *messageError) Error() string {
+	return msg(e.id)
+}
+
+// ProjectNotFoundError is returned when the workspace has no project with
+// the identifier of an issue key.
+type ProjectNotFoundError struct {
+	Identifier string
+}
+
+func (e *ProjectNotFoundError) Error() string {
+	return msg("project_not_found", e.Identifier)
+}
+
+// IssueNotFoundError is returned when the issue of a key can't be fetched;
+// Err is the error returned by the Plane client.
+type IssueNotFoundError struct {
+	Key string
+	Err error
+}
+
+func (e *IssueNotFoundError) Error() string {
+	return fmt.Errorf(msg("get_issue_failed"), e.Err).Error()
+}
+
+func (e *IssueNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned by the Client methods that need the HTTP status
+// of a failed request, such as CreateWorklog; Err is the error returned by
+// plane-api-go. Client implementations should return it when the endpoint
+// doesn't exist, so Run can fall back to another operation.
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
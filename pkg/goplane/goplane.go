@@ -0,0 +1,645 @@
+// Package goplane updates the Plane issues referenced by commit messages,
+// pull requests and other text: it comments on them, moves them to another
+// state, assigns, labels and adds them to modules, and records "#time"
+// directives as worklogs. The go-plane command is a thin wrapper around Run,
+// and other tools such as release bots can embed it the same way.
+package goplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GeekWorkCode/go-plane/pkg/markdown"
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// IssueKeyPattern matches issue keys in WORDS-1 format, e.g. "PROJ-123".
+var IssueKeyPattern = regexp.MustCompile(`([A-Z][A-Z0-9]+-[0-9]+)`)
+
+// DefaultConcurrency is the number of issues processed at the same time
+// when Config.Concurrency isn't set.
+const DefaultConcurrency = 4
+
+// Config describes one run: how to reach Plane, where to find issue keys
+// and what to do with the referenced issues. Empty operation fields are
+// skipped.
+type Config struct {
+	// BaseURL is the Plane API base URL, defaulting to Plane Cloud.
+	BaseURL string
+	// Token is the Plane API token.
+	Token string
+	// WorkspaceSlug is the workspace the issues belong to.
+	WorkspaceSlug string
+	// WorkspaceMap maps project identifiers, e.g. "PROJ", to the slug of
+	// their workspace, for organizations with several workspaces.
+	WorkspaceMap map[string]string
+	// Workspaces are searched in order, after WorkspaceSlug, for projects
+	// missing from WorkspaceMap.
+	Workspaces []string
+	// Client talks to Plane; when nil one is created from BaseURL and Token.
+	Client Client
+
+	// Ref is the text issue keys and "#time" directives are extracted from,
+	// e.g. commit messages.
+	Ref string
+	// SkipWorklogs ignores the "#time" directives in Ref, for text such as
+	// pull request bodies that is seen again by later events.
+	SkipWorklogs bool
+
+	// ToState is the name of the state issues are moved to.
+	ToState string
+	// Comment is added to every issue, converted from Markdown to HTML
+	// when Markdown is set.
+	Comment  string
+	Markdown bool
+	// IssueLinks selects how issue keys in Markdown comments are rendered:
+	// IssueLinksURL or IssueLinksMention; empty leaves them as text.
+	IssueLinks string
+	// ForgeLinks links references like "#482", "org/repo#12" and commit
+	// SHAs in Markdown comments to Event.RepositoryURL.
+	ForgeLinks bool
+	// MarkdownOptions are added to the options of Markdown comments, e.g.
+	// custom rules from markdown.WithInlineRule and markdown.WithBlockRule.
+	MarkdownOptions []markdown.Option
+	// Assignee is the display name of the member issues are assigned to.
+	Assignee string
+	// Labels are added to every issue, keeping existing labels.
+	Labels []string
+	// Module is the name of the module issues are added to, created if
+	// it doesn't exist.
+	Module string
+
+	// Rules fill unset operations depending on Event; the first matching
+	// rule applies.
+	Rules []Rule
+	// Defaults fill operations still unset after Rules, e.g. the defaults
+	// of a configuration file; When is ignored.
+	Defaults Rule
+	// Event is the CI event that triggered the run.
+	Event EventContext
+	// Author is the commit author worklogs are recorded for.
+	Author CommitAuthor
+
+	// Concurrency is the number of issues processed at the same time,
+	// DefaultConcurrency when zero.
+	Concurrency int
+	// Cache keeps project, state, label and member lists; nil disables it.
+	Cache *Cache
+	// RefreshCache ignores lists cached by previous runs.
+	RefreshCache bool
+}
+
+// Run processes every issue referenced by config.Ref. Failures of single
+// issues or operations are recorded in the result instead of aborting the
+// run.
+//
+// Parameters:
+//
+//	ctx - stops processing issues and operations that haven't started yet when done.
+//	config - the run configuration.
+//
+// Returns:
+//
+//	*Result - the result of every referenced issue, never nil.
+//	error - ErrNoIssueKeys, ErrNoIssues, a rule error or the error of ctx.
+func Run(ctx context.Context, config Config) (*Result, error) {
+	results := &Result{}
+
+	// 应用匹配当前事件的规则, 然后用默认值填充仍未设置的操作
+	// Apply the rule matching the current event, then fill operations still unset with the defaults
+	var err error
+	if r, ok := selectRule(config.Rules, config.Event); ok {
+		if config, err = applyRule(config, r, config.Event); err != nil {
+			return results, err
+		}
+	}
+	if config, err = applyRule(config, config.Defaults, config.Event); err != nil {
+		return results, err
+	}
+
+	if config.Concurrency < 1 {
+		config.Concurrency = DefaultConcurrency
+	}
+	config.Client = config.client()
+
+	// 解析引用中的issue key
+	// Parse issue keys from the reference
+	keys := IssueKeys(config.Ref)
+	if len(keys) == 0 {
+		return results, ErrNoIssueKeys
+	}
+
+	slog.Info(msg("log.processing_issues"), "keys", strings.Join(keys, ", "), "concurrency", config.Concurrency)
+
+	// 并发查询所有issue
+	// Resolve all issues concurrently
+	endGroup := logGroup(msg("log.group_resolve"))
+	resolved := resolveKeys(ctx, config, keys, results)
+	endGroup()
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	if len(resolved) == 0 {
+		return results, ErrNoIssues
+	}
+	defer logGroup(msg("log.group_update"))()
+
+	var directives map[string][]timeDirective
+	if !config.SkipWorklogs {
+		directives = parseTimeDirectives(config.Ref)
+	}
+	stages := []struct {
+		enabled bool
+		run     func(group workspaceGroup)
+	}{
+		// 添加评论
+		// Add comments
+		{config.Comment != "", func(g workspaceGroup) { addComments(ctx, g.config, g.issues, results) }},
+		// 更新状态
+		// Update state
+		{config.ToState != "", func(g workspaceGroup) { processState(ctx, g.config, g.issues, results) }},
+		// 分配责任人
+		// Assign issues
+		{config.Assignee != "", func(g workspaceGroup) { processAssignee(ctx, g.config, g.issues, results) }},
+		// 添加标签
+		// Add labels
+		{len(config.Labels) > 0, func(g workspaceGroup) { processLabels(ctx, g.config, g.issues, results) }},
+		// 加入模块
+		// Add to module
+		{config.Module != "", func(g workspaceGroup) { processModule(ctx, g.config, g.issues, results) }},
+		// 记录工时
+		// Log work time
+		{len(directives) > 0, func(g workspaceGroup) {
+			forEach(ctx, g.config.Concurrency, len(g.issues), func(i int) {
+				if len(directives[g.keys[i]]) > 0 {
+					processWorklogs(ctx, g.config, g.issues[i:i+1], directives[g.keys[i]], results)
+				}
+			})
+		}},
+	}
+
+	// 各阶段依次执行, 每个阶段内按工作区并发处理所有issue
+	// Stages run one after another, each processing all issues concurrently, workspace by workspace
+	groups := groupByWorkspace(config, resolved)
+	for _, stage := range stages {
+		if !stage.enabled {
+			continue
+		}
+		for _, group := range groups {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+			stage.run(group)
+		}
+	}
+
+	return results, ctx.Err()
+}
+
+// 返回配置的客户端, 未配置时根据地址和令牌创建
+// Return the configured client, creating one from the URL and token if unset
+func (config Config) client() Client {
+	if config.Client != nil {
+		return config.Client
+	}
+	return NewClient(config.BaseURL, config.Token)
+}
+
+// IssueKeys returns the issue keys referenced by text, deduplicated and in
+// their order of appearance.
+//
+// Parameters:
+//
+//	text - the text to search, e.g. commit messages.
+//
+// Returns:
+//
+//	[]string - the issue keys, or nil if there are none.
+func IssueKeys(text string) []string {
+	var keys []string
+	for _, key := range IssueKeyPattern.FindAllString(text, -1) {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// FindProject returns the project with the given identifier, e.g. "PROJ",
+// searching the workspaces as described by ProjectConfig.
+//
+// Parameters:
+//
+//	config - the client, workspaces and cache to use.
+//	identifier - the project identifier, compared case-insensitively.
+//
+// Returns:
+//
+//	models.Project - the project.
+//	error - a *ProjectNotFoundError, or the error of listing projects.
+func FindProject(config Config, identifier string) (models.Project, error) {
+	_, project, err := ProjectConfig(config, identifier)
+	return project, err
+}
+
+// ProjectConfig routes config to the workspace of the project with the
+// given identifier. A project in WorkspaceMap is only looked up in its
+// mapped workspace; other projects are looked up in WorkspaceSlug and then
+// in Workspaces, in order.
+//
+// Parameters:
+//
+//	config - the client, workspaces and cache to use.
+//	identifier - the project identifier, compared case-insensitively.
+//
+// Returns:
+//
+//	Config - config with WorkspaceSlug set to the workspace of the project.
+//	models.Project - the project.
+//	error - a *ProjectNotFoundError, or the error of listing projects when
+//	the project wasn't found in any other workspace.
+func ProjectConfig(config Config, identifier string) (Config, models.Project, error) {
+	config.Client = config.client()
+
+	// 先在缓存的列表中查找, 找不到时重新获取列表再查找一次, 以便找到缓存之后新建的项目
+	// Look in the cached lists first, and fetch them again for a second look when missing, so projects created after they were cached are found
+	var listErr error
+	for _, refetch := range []bool{false, true} {
+		if refetch && config.Cache == nil {
+			break
+		}
+		var found func([]models.Project) bool
+		if refetch {
+			found = func(projects []models.Project) bool { return findProject(projects, identifier) != nil }
+		}
+
+		for _, slug := range config.candidateWorkspaces(identifier) {
+			routed := config
+			routed.WorkspaceSlug = slug
+
+			projects, err := listProjects(routed, found)
+			if err != nil {
+				if listErr == nil {
+					listErr = fmt.Errorf(msg("list_projects_failed"), err)
+				}
+				continue
+			}
+			if project := findProject(projects, identifier); project != nil {
+				return routed, *project, nil
+			}
+		}
+	}
+
+	if listErr != nil {
+		return config, models.Project{}, listErr
+	}
+	return config, models.Project{}, &ProjectNotFoundError{Identifier: identifier}
+}
+
+// 按标识查找项目, 不区分大小写, 找不到时返回 nil
+// Find a project by identifier, case-insensitively, returning nil if not found
+func findProject(projects []models.Project, identifier string) *models.Project {
+	for i := range projects {
+		if strings.EqualFold(projects[i].Identifier, identifier) {
+			return &projects[i]
+		}
+	}
+	return nil
+}
+
+// 项目可能所在的工作区, 按查找顺序排列: 映射的工作区, 否则为默认工作区和其余工作区
+// Workspaces a project may belong to, in lookup order: its mapped workspace, or else the default workspace followed by the others
+func (config Config) candidateWorkspaces(identifier string) []string {
+	if slug := config.mappedWorkspace(identifier); slug != "" {
+		return []string{slug}
+	}
+
+	var slugs []string
+	for _, slug := range append([]string{config.WorkspaceSlug}, config.Workspaces...) {
+		if slug != "" && !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		return []string{config.WorkspaceSlug}
+	}
+	return slugs
+}
+
+// WorkspaceMap 中项目映射到的工作区, 标识不区分大小写, 优先精确匹配; 未映射时返回空
+// Workspace WorkspaceMap maps a project to, comparing identifiers case-insensitively and preferring an exact match; empty when unmapped
+func (config Config) mappedWorkspace(identifier string) string {
+	if slug := config.WorkspaceMap[identifier]; slug != "" {
+		return slug
+	}
+	for project, slug := range config.WorkspaceMap {
+		if strings.EqualFold(project, identifier) && slug != "" {
+			return slug
+		}
+	}
+	return ""
+}
+
+// FindIssue returns the issue with the given key, e.g. "PROJ-123", from
+// the workspace of its project.
+//
+// Parameters:
+//
+//	config - the client, workspaces and cache to use.
+//	key - the issue key.
+//
+// Returns:
+//
+//	models.Issue - the issue.
+//	error - a *ProjectNotFoundError or *IssueNotFoundError.
+func FindIssue(config Config, key string) (models.Issue, error) {
+	issue, _, err := LocateIssue(config, key)
+	return issue, err
+}
+
+// LocateIssue is like FindIssue, also returning config routed to the
+// workspace of the issue for further requests about it.
+//
+// Parameters:
+//
+//	config - the client, workspaces and cache to use.
+//	key - the issue key.
+//
+// Returns:
+//
+//	models.Issue - the issue.
+//	Config - config with WorkspaceSlug set to the workspace of the issue.
+//	error - a *ProjectNotFoundError or *IssueNotFoundError.
+func LocateIssue(config Config, key string) (models.Issue, Config, error) {
+	projectIdentifier, _, _ := strings.Cut(key, "-")
+
+	// 验证项目是否存在, 并找到其工作区
+	// Verify project exists and find its workspace
+	config, _, err := ProjectConfig(config, projectIdentifier)
+	if err != nil {
+		return models.Issue{}, config, err
+	}
+
+	// 使用完整的 key 查询issue, 只用序列号会匹配到工作区中其它项目的同号issue
+	// Query the issue by its full key; the bare number would match issues with the same number in other projects of the workspace
+	issue, err := config.Client.GetIssueBySequenceID(config.WorkspaceSlug, key)
+	if err != nil {
+		return models.Issue{}, config, &IssueNotFoundError{Key: key, Err: err}
+	}
+
+	return *issue, config, nil
+}
+
+// IssueURL derives the web URL of an issue from the API base URL.
+//
+// Parameters:
+//
+//	config - the base URL and workspace of the issue.
+//	issue - the issue.
+//
+// Returns:
+//
+//	string - the URL of the issue in the Plane web app.
+func IssueURL(config Config, issue models.Issue) string {
+	return fmt.Sprintf("%s/%s/projects/%s/issues/%s", webURL(config), config.WorkspaceSlug, issue.Project, issue.ID)
+}
+
+// 由API地址得到 Plane 网页应用的地址
+// Derive the URL of the Plane web app from the API base URL
+func webURL(config Config) string {
+	base := strings.TrimRight(config.BaseURL, "/")
+	switch {
+	case base == "":
+		return "https://app.plane.so"
+	case strings.HasPrefix(base, "https://api.plane.so"):
+		return "https://app.plane.so"
+	default:
+		base = strings.TrimSuffix(base, "/api/v1")
+		return strings.TrimSuffix(base, "/api")
+	}
+}
+
+// 查询单个issue并记录结果, 未找到时返回 false
+// Resolve a single issue and record the result, returning false if it wasn't found
+func processIssue(config Config, key string, results *Result) (resolvedIssue, bool) {
+	results.add(key)
+
+	start := time.Now()
+	issue, routed, err := LocateIssue(config, key)
+	if err != nil {
+		slog.Warn(msg("log.issue_not_found"), "key", key, "err", err)
+		results.update(key, func(r *IssueResult) { r.Error = err.Error() })
+		return resolvedIssue{}, false
+	}
+
+	results.update(key, func(r *IssueResult) {
+		r.ID = issue.ID
+		r.Name = issue.Name
+		r.URL = IssueURL(routed, issue)
+	})
+	slog.Info(msg("log.found_issue"), "key", key, "issue", issue.ID, "project", issue.Project,
+		"workspace", routed.WorkspaceSlug, "name", issue.Name, "duration", time.Since(start))
+
+	return resolvedIssue{key: key, workspaceSlug: routed.WorkspaceSlug, issue: issue}, true
+}
+
+// 添加评论
+// Add comments
+func addComments(ctx context.Context, config Config, issues []models.Issue, results *Result) {
+	commentText := config.Comment
+	if config.Markdown {
+		// 将Markdown转换为HTML
+		// Convert Markdown to HTML
+		commentText = RenderMarkdown(config, config.Comment)
+	}
+
+	forEach(ctx, config.Concurrency, len(issues), func(i int) {
+		issue := issues[i]
+		start := time.Now()
+
+		commentReq := &api.CommentRequest{
+			CommentHTML: commentText,
+		}
+
+		_, err := config.Client.CreateComment(config.WorkspaceSlug, issue.Project, issue.ID, commentReq)
+		logOperation("comment", issue, start, err)
+		results.record(issue.ID, "comment", err)
+	})
+}
+
+// 处理issue状态更新
+// Process issue state update
+func processState(ctx context.Context, config Config, issues []models.Issue, results *Result) {
+	projectStates, projectErrors := listPerProject(issues, "log.list_states_failed", func(projectID string) ([]models.State, error) {
+		return listStates(config, projectID, func(states []models.State) bool {
+			return findStateID(states, config.ToState) != ""
+		})
+	})
+
+	forEach(ctx, config.Concurrency, len(issues), func(i int) {
+		issue := issues[i]
+		if err := projectErrors[issue.Project]; err != nil {
+			results.record(issue.ID, "state", err)
+			return
+		}
+
+		start := time.Now()
+		states := projectStates[issue.Project]
+
+		// 记录原状态, 用于结果摘要
+		// Remember the previous state for the results summary
+		from := stateName(states, issue.State)
+
+		err := updateState(config, issue, states)
+		logOperation("state", issue, start, err, "from", from, "to", config.ToState)
+		if err == nil {
+			results.change(issue.ID, "state", from, config.ToState)
+		}
+		results.record(issue.ID, "state", err)
+	})
+}
+
+// 按名称查找目标状态并更新issue
+// Find the target state by name and update the issue to it
+func updateState(config Config, issue models.Issue, states []models.State) error {
+	stateID := findStateID(states, config.ToState)
+	if stateID == "" {
+		return errors.New(msg("state_not_found", config.ToState))
+	}
+
+	updateReq := &api.IssueUpdateRequest{
+		State: stateID,
+	}
+	_, err := config.Client.UpdateIssue(config.WorkspaceSlug, issue.Project, issue.ID, updateReq)
+	return err
+}
+
+// 处理issue分配
+// Process issue assignment
+func processAssignee(ctx context.Context, config Config, issues []models.Issue, results *Result) {
+	projectMembers, projectErrors := listPerProject(issues, "log.list_members_failed", func(projectID string) ([]models.Member, error) {
+		return listMembers(config, projectID, func(members []models.Member) bool {
+			return findMemberByName(members, config.Assignee) != ""
+		})
+	})
+
+	forEach(ctx, config.Concurrency, len(issues), func(i int) {
+		issue := issues[i]
+		if err := projectErrors[issue.Project]; err != nil {
+			results.record(issue.ID, "assign", err)
+			return
+		}
+
+		start := time.Now()
+		members := projectMembers[issue.Project]
+
+		// 记录原分配人, 用于结果摘要
+		// Remember the previous assignees for the results summary
+		from := strings.Join(memberNames(members, issue.Assignees), ", ")
+
+		err := updateAssignee(config, issue, members)
+		logOperation("assign", issue, start, err, "assignee", config.Assignee)
+		if err == nil {
+			results.change(issue.ID, "assignees", from, config.Assignee)
+		}
+		results.record(issue.ID, "assign", err)
+	})
+}
+
+// 按显示名称查找分配人并更新issue
+// Find the assignee by display name and update the issue to it
+func updateAssignee(config Config, issue models.Issue, members []models.Member) error {
+	memberID := findMemberByName(members, config.Assignee)
+	if memberID == "" {
+		return errors.New(msg("member_not_found", config.Assignee))
+	}
+
+	updateReq := &api.IssueUpdateRequest{
+		Assignees: []string{memberID},
+	}
+	_, err := config.Client.UpdateIssue(config.WorkspaceSlug, issue.Project, issue.ID, updateReq)
+	return err
+}
+
+// 按名称查找状态ID, 找不到时返回空字符串
+// Find the ID of a state by name, returning an empty string if not found
+func findStateID(states []models.State, name string) string {
+	for _, state := range states {
+		if state.Name == name {
+			return state.ID
+		}
+	}
+	return ""
+}
+
+// 按显示名称查找成员ID, 找不到时返回空字符串
+// Find the ID of a member by display name, returning an empty string if not found
+func findMemberByName(members []models.Member, name string) string {
+	for _, member := range members {
+		if member.Member.DisplayName == name {
+			return member.Member.ID
+		}
+	}
+	return ""
+}
+
+// 并发处理前按项目获取列表, 每个项目只获取一次; 获取失败时记录日志 logID 并保存错误
+// Fetch a list for each project before processing concurrently, once per project; failures are logged as logID and their errors kept
+func listPerProject[T any](issues []models.Issue, logID string, list func(projectID string) ([]T, error)) (map[string][]T, map[string]error) {
+	lists := make(map[string][]T)
+	errs := make(map[string]error)
+	for _, issue := range issues {
+		if _, ok := lists[issue.Project]; ok || errs[issue.Project] != nil {
+			continue
+		}
+		items, err := list(issue.Project)
+		if err != nil {
+			slog.Warn(msg(logID), "project", issue.Project, "err", err)
+			errs[issue.Project] = err
+			continue
+		}
+		lists[issue.Project] = items
+	}
+	return lists, errs
+}
+
+// 根据状态ID查找状态名称, 找不到时返回ID
+// Find the name of a state by ID, returning the ID if not found
+func stateName(states []models.State, stateID string) string {
+	for _, state := range states {
+		if state.ID == stateID {
+			return state.Name
+		}
+	}
+	return stateID
+}
+
+// 根据成员ID查找显示名称, 找不到时保留ID
+// Find the display names of members by ID, keeping the ID if not found
+func memberNames(members []models.Member, memberIDs []string) []string {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	names := make(map[string]string)
+	for _, member := range members {
+		names[member.Member.ID] = member.Member.DisplayName
+	}
+
+	result := make([]string, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if name, ok := names[id]; ok {
+			result = append(result, name)
+		} else {
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,300 @@
+package goplane
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 内存中的 Client, 记录所有写操作
+// In-memory Client recording every write
+type fakeClient struct {
+	mu      sync.Mutex
+	issues  map[string]models.Issue
+	labels  []string
+	calls   []string
+	listErr error
+	// 记录工时返回的错误
+	// Error returned when creating worklogs
+	worklogErr error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{issues: map[string]models.Issue{
+		"PROJ-1": {ID: "i1", Name: "Login page", Project: "p1", State: "s1"},
+		"PROJ-2": {ID: "i2", Name: "Crash", Project: "p1"},
+	}}
+}
+
+func (c *fakeClient) call(format string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, format)
+}
+
+func (c *fakeClient) ListProjects(string) ([]models.Project, error) {
+	return []models.Project{{ID: "p1", Identifier: "PROJ"}}, c.listErr
+}
+
+func (c *fakeClient) ListStates(string, string) ([]models.State, error) {
+	return []models.State{{ID: "s1", Name: "Todo"}, {ID: "s2", Name: "Done"}}, nil
+}
+
+func (c *fakeClient) ListLabels(string, string) ([]models.Label, error) {
+	return []models.Label{{ID: "l1", Name: "released"}}, nil
+}
+
+func (c *fakeClient) ListMembers(string, string) ([]models.Member, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) ListModules(string, string) ([]models.Module, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) ListIssueTypes(string, string) ([]IssueType, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) GetIssueBySequenceID(_, key string) (*models.Issue, error) {
+	issue, ok := c.issues[key]
+	if !ok {
+		return nil, errors.New("404 not found")
+	}
+	return &issue, nil
+}
+
+func (c *fakeClient) GetIssueDetails(string, string, string) (*IssueDetails, error) {
+	return &IssueDetails{Labels: c.labels}, nil
+}
+
+func (c *fakeClient) UpdateIssue(_, _, issueID string, req *api.IssueUpdateRequest) (*models.Issue, error) {
+	c.call("update " + issueID + " " + req.State)
+	return &models.Issue{ID: issueID}, nil
+}
+
+func (c *fakeClient) SetIssueLabels(_, _, issueID string, labelIDs []string) error {
+	c.call("labels " + issueID + " " + labelIDs[0])
+	return nil
+}
+
+func (c *fakeClient) CreateComment(_, _, issueID string, req *api.CommentRequest) (*models.Comment, error) {
+	c.call("comment " + issueID + " " + req.CommentHTML)
+	return &models.Comment{}, nil
+}
+
+func (c *fakeClient) CreateWorklog(_, _, issueID string, req *api.WorklogCreateRequest) (*models.Worklog, error) {
+	c.call("worklog " + issueID)
+	if c.worklogErr != nil {
+		return nil, c.worklogErr
+	}
+	return &models.Worklog{}, nil
+}
+
+func (c *fakeClient) CreateModule(_, _ string, req *api.ModuleCreateRequest) (*models.Module, error) {
+	c.call("create module " + req.Name)
+	return &models.Module{ID: "m1", Name: req.Name}, nil
+}
+
+func (c *fakeClient) AddModuleIssues(_, _, moduleID string, issueIDs []string) error {
+	c.call("module " + moduleID)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	planeClient := newFakeClient()
+	config := Config{
+		WorkspaceSlug: "ws",
+		Client:        planeClient,
+		Ref:           "PROJ-1 PROJ-9 fix login #time 1h",
+		Comment:       "Fixed",
+		ToState:       "Done",
+		Labels:        []string{"released"},
+		Module:        "v1.0",
+	}
+
+	results, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	slices.Sort(planeClient.calls)
+	want := []string{"comment i1 Fixed", "create module v1.0", "labels i1 l1", "module m1", "update i1 s2", "worklog i1"}
+	if !reflect.DeepEqual(planeClient.calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, planeClient.calls)
+	}
+
+	if len(results.Issues) != 2 || results.Issues[0].Key != "PROJ-1" || results.Issues[1].Key != "PROJ-9" {
+		t.Fatalf("Unexpected results %+v", results.Issues)
+	}
+	if change := results.Issues[0].State; change == nil || change.From != "Todo" || change.To != "Done" {
+		t.Errorf("Expected state change Todo → Done, got %+v", change)
+	}
+	if got := results.FailedCount(); got != 1 {
+		t.Errorf("Expected 1 failed issue, got %d", got)
+	}
+}
+
+func TestRunSkipWorklogs(t *testing.T) {
+	planeClient := newFakeClient()
+	config := Config{
+		WorkspaceSlug: "ws",
+		Client:        planeClient,
+		Ref:           "PROJ-1 login #time 1h",
+		SkipWorklogs:  true,
+		Comment:       "Fixed",
+	}
+
+	if _, err := Run(context.Background(), config); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"comment i1 Fixed"}; !reflect.DeepEqual(planeClient.calls, want) {
+		t.Errorf("Expected calls %v, got %v", want, planeClient.calls)
+	}
+}
+
+func TestRunDefaults(t *testing.T) {
+	rules := []Rule{{Comment: "Merged into {{branch}}"}}
+	rules[0].When.Event = "push"
+
+	// 优先级: 已设置的值 > 匹配的规则 > 默认值
+	// Precedence: values already set > matching rule > defaults
+	tests := []struct {
+		name    string
+		comment string
+		event   string
+		want    string
+	}{
+		{name: "rule over defaults", event: "push", want: "comment i1 Merged into main"},
+		{name: "set value over rule", comment: "Fixed", event: "push", want: "comment i1 Fixed"},
+		{name: "defaults without rule", event: "pull_request", want: "comment i1 Linked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planeClient := newFakeClient()
+			config := Config{
+				WorkspaceSlug: "ws",
+				Client:        planeClient,
+				Ref:           "PROJ-1",
+				Comment:       tt.comment,
+				Rules:         rules,
+				Defaults:      Rule{Comment: "Linked"},
+				Event:         EventContext{Event: tt.event, Branch: "main"},
+			}
+
+			if _, err := Run(context.Background(), config); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if want := []string{tt.want}; !reflect.DeepEqual(planeClient.calls, want) {
+				t.Errorf("Expected calls %v, got %v", want, planeClient.calls)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		ref  string
+		want error
+	}{
+		{name: "no keys", ctx: context.Background(), ref: "update readme", want: ErrNoIssueKeys},
+		{name: "no issues", ctx: context.Background(), ref: "PROJ-9", want: ErrNoIssues},
+		{name: "canceled", ctx: canceled, ref: "PROJ-1", want: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planeClient := newFakeClient()
+			results, err := Run(tt.ctx, Config{Client: planeClient, Ref: tt.ref, Comment: "Fixed"})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Run() error = %v, want %v", err, tt.want)
+			}
+			if results == nil {
+				t.Error("Expected a result")
+			}
+			if len(planeClient.calls) != 0 {
+				t.Errorf("Expected no writes, got %v", planeClient.calls)
+			}
+		})
+	}
+}
+
+func TestFindIssue(t *testing.T) {
+	planeClient := newFakeClient()
+	config := Config{WorkspaceSlug: "ws", Client: planeClient}
+
+	issue, err := FindIssue(config, "PROJ-1")
+	if err != nil || issue.ID != "i1" {
+		t.Errorf("FindIssue(PROJ-1) = %+v, %v", issue, err)
+	}
+
+	var projectErr *ProjectNotFoundError
+	if _, err := FindIssue(config, "NOPE-1"); !errors.As(err, &projectErr) || projectErr.Identifier != "NOPE" {
+		t.Errorf("Expected a ProjectNotFoundError, got %v", err)
+	}
+
+	var issueErr *IssueNotFoundError
+	if _, err := FindIssue(config, "PROJ-9"); !errors.As(err, &issueErr) || issueErr.Key != "PROJ-9" {
+		t.Errorf("Expected an IssueNotFoundError, got %v", err)
+	}
+
+	planeClient.listErr = errors.New("unavailable")
+	if _, err := FindIssue(config, "PROJ-1"); err == nil || errors.As(err, &projectErr) {
+		t.Errorf("Expected the list error, got %v", err)
+	}
+}
+
+func TestIssueKeys(t *testing.T) {
+	got := IssueKeys("PROJ-1 login\nPROJ-2 fix PROJ-1\nOPS-7")
+	want := []string{"PROJ-1", "PROJ-2", "OPS-7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IssueKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestIssueURL(t *testing.T) {
+	issue := models.Issue{ID: "issue-id", Project: "project-id"}
+
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{
+			name:    "default cloud",
+			baseURL: "",
+			want:    "https://app.plane.so/ws/projects/project-id/issues/issue-id",
+		},
+		{
+			name:    "cloud api",
+			baseURL: "https://api.plane.so/api/v1",
+			want:    "https://app.plane.so/ws/projects/project-id/issues/issue-id",
+		},
+		{
+			name:    "self hosted",
+			baseURL: "https://plane.example.com/api/v1/",
+			want:    "https://plane.example.com/ws/projects/project-id/issues/issue-id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IssueURL(Config{BaseURL: tt.baseURL, WorkspaceSlug: "ws"}, issue)
+			if got != tt.want {
+				t.Errorf("IssueURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
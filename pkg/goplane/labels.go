@@ -0,0 +1,132 @@
+package goplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 为issue添加标签, 保留已有标签
+// Add labels to issues, keeping existing ones
+func processLabels(ctx context.Context, config Config, issues []models.Issue, results *Result) {
+	projectLabels, projectErrors := listPerProject(issues, "log.list_labels_failed", func(projectID string) ([]models.Label, error) {
+		return listLabels(config, projectID, func(labels []models.Label) bool {
+			for _, name := range config.Labels {
+				if findLabelID(labels, name) == "" {
+					return false
+				}
+			}
+			return true
+		})
+	})
+
+	forEach(ctx, config.Concurrency, len(issues), func(i int) {
+		issue := issues[i]
+		if err := projectErrors[issue.Project]; err != nil {
+			results.record(issue.ID, "labels", err)
+			return
+		}
+
+		start := time.Now()
+		err := addIssueLabels(config, issue, projectLabels[issue.Project])
+		logOperation("labels", issue, start, err, "labels", strings.Join(config.Labels, ", "))
+		results.record(issue.ID, "labels", err)
+	})
+}
+
+func addIssueLabels(config Config, issue models.Issue, labels []models.Label) error {
+	current, err := config.Client.GetIssueDetails(config.WorkspaceSlug, issue.Project, issue.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := current.Labels
+	for _, name := range config.Labels {
+		id := findLabelID(labels, name)
+		if id == "" {
+			return errors.New(msg("label_not_found", name))
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == len(current.Labels) {
+		return nil
+	}
+
+	return config.Client.SetIssueLabels(config.WorkspaceSlug, issue.Project, issue.ID, ids)
+}
+
+// 按名称查找标签ID, 不区分大小写, 找不到时返回空字符串
+// Find the ID of a label by name, case-insensitively, returning an empty string if not found
+func findLabelID(labels []models.Label, name string) string {
+	for _, label := range labels {
+		if strings.EqualFold(label.Name, name) {
+			return label.ID
+		}
+	}
+	return ""
+}
+
+// 将issue加入模块, 模块不存在时自动创建
+// Add issues to a module, creating the module if it doesn't exist
+func processModule(ctx context.Context, config Config, issues []models.Issue, results *Result) {
+	byProject := make(map[string][]string)
+	var projects []string
+	for _, issue := range issues {
+		if _, ok := byProject[issue.Project]; !ok {
+			projects = append(projects, issue.Project)
+		}
+		byProject[issue.Project] = append(byProject[issue.Project], issue.ID)
+	}
+
+	for _, projectID := range projects {
+		if ctx.Err() != nil {
+			return
+		}
+		start := time.Now()
+		moduleID, err := findOrCreateModule(config, projectID)
+		if err == nil {
+			err = config.Client.AddModuleIssues(config.WorkspaceSlug, projectID, moduleID, byProject[projectID])
+		}
+
+		attrs := []any{"op", "module", "project", projectID, "module", config.Module,
+			"issues", len(byProject[projectID]), "duration", time.Since(start)}
+		if err != nil {
+			slog.Warn(msg("log.module_failed"), append(attrs, "err", err)...)
+		} else {
+			slog.Info(msg("log.module_done"), attrs...)
+		}
+
+		for _, issueID := range byProject[projectID] {
+			results.record(issueID, "module", err)
+		}
+	}
+}
+
+func findOrCreateModule(config Config, projectID string) (string, error) {
+	modules, err := config.Client.ListModules(config.WorkspaceSlug, projectID)
+	if err != nil {
+		return "", fmt.Errorf(msg("list_modules_failed"), err)
+	}
+
+	for _, module := range modules {
+		if strings.EqualFold(module.Name, config.Module) {
+			return module.ID, nil
+		}
+	}
+
+	module, err := config.Client.CreateModule(config.WorkspaceSlug, projectID, &api.ModuleCreateRequest{Name: config.Module})
+	if err != nil {
+		return "", fmt.Errorf(msg("create_module_failed"), err)
+	}
+	return module.ID, nil
+}
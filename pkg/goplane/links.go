@@ -0,0 +1,95 @@
+package goplane
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/GeekWorkCode/go-plane/pkg/markdown"
+)
+
+// Values of Config.IssueLinks.
+const (
+	// IssueLinksURL links issue keys to the issue in the Plane web app.
+	IssueLinksURL = "link"
+	// IssueLinksMention renders issue keys with Plane's issue mention
+	// markup, looking up each issue; unknown keys are left as text.
+	IssueLinksMention = "mention"
+)
+
+// RenderMarkdown converts Markdown text to HTML, rendering the issue keys
+// in it as selected by config.IssueLinks, linking forge references when
+// config.ForgeLinks is set, and applying config.MarkdownOptions.
+//
+// Parameters:
+//
+//	config - the link settings, and the base URL, workspaces and client used to find issues.
+//	text - the Markdown text.
+//
+// Returns:
+//
+//	string - the HTML.
+func RenderMarkdown(config Config, text string) string {
+	return ParseMarkdown(config, text).HTML()
+}
+
+// ParseMarkdown parses Markdown text with the same links as
+// RenderMarkdown, so a comment can also be rendered as plain text or
+// Slack mrkdwn consistently with the one posted to Plane. Issue keys keep
+// their key as text outside HTML.
+//
+// Parameters:
+//
+//	config - the link settings, and the base URL, workspaces and client used to find issues.
+//	text - the Markdown text.
+//
+// Returns:
+//
+//	*markdown.Document - the parsed document.
+func ParseMarkdown(config Config, text string) *markdown.Document {
+	var opts []markdown.Option
+	if config.ForgeLinks {
+		opts = append(opts, markdown.WithReferences(config.Event.RepositoryURL))
+	}
+
+	switch config.IssueLinks {
+	case IssueLinksURL:
+		opts = append(opts, markdown.WithIssueKeys(IssueKeyPattern, func(key string) string {
+			return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(IssueKeyURL(config, key)), key)
+		}))
+	case IssueLinksMention:
+		config.Client = config.client()
+		opts = append(opts, markdown.WithIssueKeys(IssueKeyPattern, func(key string) string {
+			issue, _, err := LocateIssue(config, key)
+			if err != nil {
+				return ""
+			}
+			return fmt.Sprintf(`<mention-component entity_identifier="%s" entity_name="issue_mention"></mention-component>`, html.EscapeString(issue.ID))
+		}))
+	}
+
+	return markdown.Parse(text, append(opts, config.MarkdownOptions...)...)
+}
+
+// IssueKeyURL derives the web URL of an issue from its key, without
+// looking the issue up. The workspace is the one config.WorkspaceMap maps
+// the project identifier to, compared case-insensitively, or
+// config.WorkspaceSlug.
+//
+// Parameters:
+//
+//	config - the base URL and workspaces.
+//	key - the issue key, e.g. "PROJ-40".
+//
+// Returns:
+//
+//	string - the URL of the issue in the Plane web app.
+func IssueKeyURL(config Config, key string) string {
+	workspace := config.WorkspaceSlug
+	if identifier, _, ok := strings.Cut(key, "-"); ok {
+		if mapped := config.mappedWorkspace(identifier); mapped != "" {
+			workspace = mapped
+		}
+	}
+	return fmt.Sprintf("%s/%s/browse/%s/", webURL(config), workspace, key)
+}
@@ -0,0 +1,139 @@
+package goplane
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/GeekWorkCode/go-plane/pkg/markdown"
+	"github.com/GeekWorkCode/go-plane/pkg/planetest"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func TestIssueKeyURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		key     string
+		want    string
+	}{
+		{name: "default cloud", key: "PROJ-40", want: "https://app.plane.so/ws/browse/PROJ-40/"},
+		{name: "self hosted", baseURL: "https://plane.example.com/api/v1", key: "PROJ-40", want: "https://plane.example.com/ws/browse/PROJ-40/"},
+		{name: "mapped workspace", key: "OPS-2", want: "https://app.plane.so/ops/browse/OPS-2/"},
+		{name: "mapped case-insensitively", key: "DATA-3", want: "https://app.plane.so/warehouse/browse/DATA-3/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{BaseURL: tt.baseURL, WorkspaceSlug: "ws", WorkspaceMap: map[string]string{"OPS": "ops", "data": "warehouse"}}
+			if got := IssueKeyURL(config, tt.key); got != tt.want {
+				t.Errorf("IssueKeyURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	server := planetest.NewServer()
+	defer server.Close()
+	server.Seed("ws", planetest.Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}, {ID: "p2", Identifier: "OPS"}},
+		Issues: []planetest.Issue{
+			{Issue: models.Issue{ID: "i40", Project: "p1"}, SequenceID: 40},
+			{Issue: models.Issue{ID: "o40", Project: "p2"}, SequenceID: 40},
+		},
+	})
+
+	tests := []struct {
+		name       string
+		issueLinks string
+		forgeLinks bool
+		text       string
+		want       string
+	}{
+		{
+			name: "text",
+			want: "duplicate of PROJ-40, not PROJ-9 or <code>PROJ-40</code>",
+		},
+		{
+			name:       "link",
+			issueLinks: IssueLinksURL,
+			want:       `duplicate of <a href="` + server.URL + `/ws/browse/PROJ-40/">PROJ-40</a>, not <a href="` + server.URL + `/ws/browse/PROJ-9/">PROJ-9</a> or <code>PROJ-40</code>`,
+		},
+		{
+			name:       "mention",
+			issueLinks: IssueLinksMention,
+			want:       `duplicate of <mention-component entity_identifier="i40" entity_name="issue_mention"></mention-component>, not PROJ-9 or <code>PROJ-40</code>`,
+		},
+		{
+			// OPS-40 和 PROJ-40 序列号相同, 须提及 OPS 项目的issue
+			// OPS-40 shares its number with PROJ-40 and must mention the OPS issue
+			name:       "mention with shared sequence number",
+			issueLinks: IssueLinksMention,
+			text:       "duplicate of OPS-40",
+			want:       `duplicate of <mention-component entity_identifier="o40" entity_name="issue_mention"></mention-component>`,
+		},
+		{
+			name:       "forge",
+			forgeLinks: true,
+			text:       "fixes #12 in abc1234",
+			want:       `fixes <a href="https://github.com/org/repo/issues/12">#12</a> in <a href="https://github.com/org/repo/commit/abc1234">abc1234</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{
+				BaseURL:       server.BaseURL(),
+				WorkspaceSlug: "ws",
+				IssueLinks:    tt.issueLinks,
+				ForgeLinks:    tt.forgeLinks,
+				Event:         EventContext{RepositoryURL: "https://github.com/org/repo"},
+				Client:        NewClient(server.BaseURL(), "token"),
+			}
+			text := tt.text
+			if text == "" {
+				text = "duplicate of PROJ-40, not PROJ-9 or `PROJ-40`"
+			}
+			if got := RenderMarkdown(config, text); got != tt.want {
+				t.Errorf("RenderMarkdown() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	config := Config{
+		WorkspaceSlug: "ws",
+		IssueLinks:    IssueLinksURL,
+		ForgeLinks:    true,
+		Event:         EventContext{RepositoryURL: "https://github.com/org/repo"},
+	}
+	doc := ParseMarkdown(config, "**PROJ-40** fixes #12")
+
+	if want := `<strong><a href="https://app.plane.so/ws/browse/PROJ-40/">PROJ-40</a></strong> fixes <a href="https://github.com/org/repo/issues/12">#12</a>`; doc.HTML() != want {
+		t.Errorf("HTML() = %s, want %s", doc.HTML(), want)
+	}
+	if want := "PROJ-40 fixes #12 (https://github.com/org/repo/issues/12)"; doc.Text() != want {
+		t.Errorf("Text() = %s, want %s", doc.Text(), want)
+	}
+	if want := "*PROJ-40* fixes <https://github.com/org/repo/issues/12|#12>"; doc.Slack() != want {
+		t.Errorf("Slack() = %s, want %s", doc.Slack(), want)
+	}
+}
+
+func TestParseMarkdownOptions(t *testing.T) {
+	config := Config{
+		WorkspaceSlug: "ws",
+		IssueLinks:    IssueLinksURL,
+		MarkdownOptions: []markdown.Option{
+			markdown.WithInlineRule(regexp.MustCompile(`:rocket:`), func([]string) []markdown.Inline {
+				return []markdown.Inline{&markdown.Text{Value: "🚀"}}
+			}),
+		},
+	}
+
+	want := `<code>:rocket:</code> 🚀 <a href="https://app.plane.so/ws/browse/PROJ-1/">PROJ-1</a>`
+	if got := RenderMarkdown(config, "`:rocket:` :rocket: PROJ-1"); got != want {
+		t.Errorf("RenderMarkdown() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package goplane
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// GroupHandler is implemented by slog handlers that can fold log records
+// into collapsible groups, such as GitHub Actions log groups. Run groups
+// its records when the default logger's handler implements it.
+type GroupHandler interface {
+	slog.Handler
+
+	// Group starts a group with the given title, returning the function
+	// that ends it.
+	Group(title string) (end func())
+}
+
+// 记录对issue的一次操作及其耗时, 成功为 info, 失败为 warn
+// Log an operation on an issue and its duration: info on success, warn on failure
+func logOperation(op string, issue models.Issue, start time.Time, err error, attrs ...any) {
+	attrs = append([]any{"op", op, "issue", issue.ID, "project", issue.Project, "duration", time.Since(start)}, attrs...)
+	if err != nil {
+		slog.Warn(msg("log.op_failed", op), append(attrs, "err", err)...)
+		return
+	}
+	slog.Info(msg("log.op_done", op), attrs...)
+}
+
+// 开始一个可折叠的日志分组, 返回结束分组的函数; 日志处理器不支持分组时不做任何事
+// Start a collapsible log group, returning the function that ends it; does nothing when the handler doesn't support groups
+func logGroup(title string) func() {
+	h, ok := slog.Default().Handler().(GroupHandler)
+	if !ok {
+		return func() {}
+	}
+	return h.Group(title)
+}
@@ -0,0 +1,60 @@
+package goplane
+
+import (
+	"embed"
+	"io/fs"
+	"sync/atomic"
+
+	"github.com/GeekWorkCode/go-plane/pkg/i18n"
+)
+
+// 各语言的消息目录, 新增语言只需添加 locales/<语言>.json
+// Message catalogs of each language; adding a language only requires a new locales/<lang>.json
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// 当前语言的消息目录, 默认为英文; 可能在运行中被 SetLanguage 替换
+// Message catalog of the current language, English by default; SetLanguage may replace it while running
+var messages atomic.Pointer[i18n.Catalog]
+
+func init() {
+	messages.Store(loadMessages(i18n.DefaultLanguage))
+}
+
+// SetLanguage selects the language of log messages and errors, e.g.
+// "zh-CN" or a locale such as "zh_CN.UTF-8"; unknown languages fall back to
+// English. It is safe to call while other goroutines run.
+func SetLanguage(lang string) {
+	messages.Store(loadMessages(lang))
+}
+
+// Messages returns the message catalog of the language selected by
+// SetLanguage, e.g. for a program to layer its own messages on with
+// i18n.Catalog.Layer.
+func Messages() *i18n.Catalog {
+	return messages.Load()
+}
+
+// 加载最匹配 lang 的消息目录
+// Load the message catalog best matching lang
+func loadMessages(lang string) *i18n.Catalog {
+	locales, err := fs.Sub(localeFiles, "locales")
+	if err != nil {
+		panic(err)
+	}
+
+	// 目录随程序一起编译, 加载失败说明目录文件有误
+	// The catalogs are compiled into the binary, so a failure means a broken catalog file
+	catalog, err := i18n.Load(locales, lang)
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+}
+
+// 返回当前语言的消息
+// Return the message in the current language
+func msg(id string, args ...any) string {
+	return messages.Load().T(id, args...)
+}
@@ -0,0 +1,94 @@
+package goplane
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 以最多 concurrency 个并发执行 fn(0) ... fn(n-1), 全部完成后返回; ctx 结束后不再启动新的调用
+// Run fn(0) ... fn(n-1) with at most concurrency running at once, returning when all are done; no new calls start once ctx is done
+func forEach(ctx context.Context, concurrency, n int, fn func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range n {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// 查询到的issue及其所在工作区
+// A resolved issue and the workspace it belongs to
+type resolvedIssue struct {
+	key           string
+	workspaceSlug string
+	issue         models.Issue
+}
+
+// 同一工作区的issue, 以及路由到该工作区的配置
+// The issues of one workspace, with the configuration routed to it
+type workspaceGroup struct {
+	config Config
+	keys   []string
+	issues []models.Issue
+}
+
+// 并发查询issue key, 按 keys 的顺序返回找到的issue
+// Resolve issue keys concurrently, returning the issues found in the order of keys
+func resolveKeys(ctx context.Context, config Config, keys []string, results *Result) []resolvedIssue {
+	// 先按顺序登记, 保证输出顺序与引用顺序一致
+	// Register the keys in order first so the output follows the order of the references
+	for _, key := range keys {
+		results.add(key)
+	}
+
+	resolved := make([]resolvedIssue, len(keys))
+	found := make([]bool, len(keys))
+	forEach(ctx, config.Concurrency, len(keys), func(i int) {
+		resolved[i], found[i] = processIssue(config, keys[i], results)
+	})
+
+	var issues []resolvedIssue
+	for i := range keys {
+		if found[i] {
+			issues = append(issues, resolved[i])
+		}
+	}
+	return issues
+}
+
+// 按工作区对issue分组, 组和组内issue保持原有顺序
+// Group issues by workspace, keeping the order of groups and of the issues in them
+func groupByWorkspace(config Config, resolved []resolvedIssue) []workspaceGroup {
+	var groups []workspaceGroup
+	for _, r := range resolved {
+		i := slices.IndexFunc(groups, func(g workspaceGroup) bool { return g.config.WorkspaceSlug == r.workspaceSlug })
+		if i < 0 {
+			group := workspaceGroup{config: config}
+			group.config.WorkspaceSlug = r.workspaceSlug
+			groups = append(groups, group)
+			i = len(groups) - 1
+		}
+		groups[i].keys = append(groups[i].keys, r.key)
+		groups[i].issues = append(groups[i].issues, r.issue)
+	}
+	return groups
+}
@@ -0,0 +1,109 @@
+package goplane
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEach(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 3, 10} {
+		var running, peak atomic.Int32
+		done := make([]bool, 20)
+
+		forEach(context.Background(), concurrency, len(done), func(i int) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			done[i] = true
+			running.Add(-1)
+		})
+
+		for i, ok := range done {
+			if !ok {
+				t.Errorf("concurrency %d: fn(%d) was not called", concurrency, i)
+			}
+		}
+		if limit := max(concurrency, 1); int(peak.Load()) > limit {
+			t.Errorf("concurrency %d: %d calls ran at once", concurrency, peak.Load())
+		}
+	}
+}
+
+func TestForEachCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+	forEach(ctx, 1, 10, func(i int) {
+		if calls.Add(1) == 3 {
+			cancel()
+		}
+	})
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected no calls to start after cancel, got %d calls", got)
+	}
+}
+
+func TestResolveKeysOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/workspaces/ws/projects/":
+			_, _ = w.Write([]byte(`{"results": [{"id": "p1", "identifier": "PROJ"}]}`))
+		case strings.HasPrefix(r.URL.Path, "/workspaces/ws/issues/"):
+			key := strings.Trim(strings.TrimPrefix(r.URL.Path, "/workspaces/ws/issues/"), "/")
+			seq, ok := strings.CutPrefix(key, "PROJ-")
+			if !ok || seq == "404" {
+				http.Error(w, `{"error": "not found"}`, http.StatusNotFound)
+				return
+			}
+			// 先引用的issue响应更慢, 验证输出顺序不受完成顺序影响
+			// Earlier issues answer slower, checking the output doesn't follow completion order
+			if seq == "1" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			_, _ = w.Write([]byte(`{"id": "i` + seq + `", "name": "Issue ` + seq + `", "project": "p1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	config := Config{BaseURL: srv.URL, WorkspaceSlug: "ws", Token: "test-token", Concurrency: 4}
+	config.Client = config.client()
+	results := &Result{}
+	keys := []string{"PROJ-1", "PROJ-404", "PROJ-2", "PROJ-3"}
+
+	resolved := resolveKeys(context.Background(), config, keys, results)
+
+	if len(results.Issues) != len(keys) {
+		t.Fatalf("Expected %d results, got %d", len(keys), len(results.Issues))
+	}
+	for i, key := range keys {
+		if got := results.Issues[i].Key; got != key {
+			t.Errorf("Expected result %d to be %s, got %s", i, key, got)
+		}
+	}
+	if results.Issues[1].Error == "" {
+		t.Errorf("Expected PROJ-404 to fail, got %+v", results.Issues[1])
+	}
+
+	var got []string
+	for _, r := range resolved {
+		got = append(got, r.key+"="+r.issue.ID)
+	}
+	if want := []string{"PROJ-1=i1", "PROJ-2=i2", "PROJ-3=i3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected resolved issues %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,155 @@
+package goplane
+
+import "sync"
+
+// ActionResult is the result of an operation on an issue, e.g. "comment"
+// or "state".
+type ActionResult struct {
+	Action  string `json:"action"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Change is the change of an issue field made by a run.
+type Change struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IssueResult is the result of a referenced issue. Error is set when the
+// issue wasn't found, and no operations ran on it.
+type IssueResult struct {
+	Key       string         `json:"key"`
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	URL       string         `json:"url,omitempty"`
+	Actions   []ActionResult `json:"actions"`
+	State     *Change        `json:"state,omitempty"`
+	Assignees *Change        `json:"assignees,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// Action returns the result of the named operation, if it ran.
+func (r *IssueResult) Action(name string) (ActionResult, bool) {
+	for _, action := range r.Actions {
+		if action.Action == name {
+			return action, true
+		}
+	}
+	return ActionResult{}, false
+}
+
+// Failed reports whether the issue wasn't found or any operation failed.
+func (r *IssueResult) Failed() bool {
+	if r.Error != "" {
+		return true
+	}
+	for _, action := range r.Actions {
+		if !action.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// Result holds the results of the issues referenced by a run, in their
+// order of appearance.
+type Result struct {
+	mu     sync.Mutex
+	Issues []*IssueResult `json:"issues"`
+}
+
+// 添加issue结果, 按key去重, results 为 nil 时忽略
+// Add an issue result, deduplicated by key; ignored when results is nil
+func (r *Result) add(key string) *IssueResult {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, issue := range r.Issues {
+		if issue.Key == key {
+			return issue
+		}
+	}
+	issue := &IssueResult{Key: key, Actions: []ActionResult{}}
+	r.Issues = append(r.Issues, issue)
+	return issue
+}
+
+// 修改指定key的issue结果, results 为 nil 时忽略
+// Update the issue result with the given key; ignored when results is nil
+func (r *Result) update(key string, fn func(*IssueResult)) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, issue := range r.Issues {
+		if issue.Key == key {
+			fn(issue)
+			return
+		}
+	}
+}
+
+// 记录issue的操作结果, results 为 nil 时忽略
+// Record the result of an action on an issue; ignored when results is nil
+func (r *Result) record(issueID, action string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, issue := range r.Issues {
+		if issue.ID != issueID {
+			continue
+		}
+		result := ActionResult{Action: action, Success: err == nil}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		issue.Actions = append(issue.Actions, result)
+		return
+	}
+}
+
+// 记录issue字段的变更, results 为 nil 时忽略
+// Record a change of an issue field; ignored when results is nil
+func (r *Result) change(issueID, field, from, to string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, issue := range r.Issues {
+		if issue.ID != issueID {
+			continue
+		}
+		switch field {
+		case "state":
+			issue.State = &Change{From: from, To: to}
+		case "assignees":
+			issue.Assignees = &Change{From: from, To: to}
+		}
+		return
+	}
+}
+
+// FailedCount returns the number of failed issues.
+func (r *Result) FailedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Failed() {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,45 @@
+package goplane
+
+import (
+	"errors"
+	"testing"
+)
+
+func testResults() *Result {
+	results := &Result{}
+
+	found := results.add("PROJ-1")
+	found.ID = "issue-1"
+	found.URL = "https://plane.example.com/ws/projects/p/issues/issue-1"
+	results.record("issue-1", "comment", nil)
+	results.record("issue-1", "state", errors.New("state not found"))
+
+	missing := results.add("PROJ-2")
+	missing.Error = "issue not found"
+
+	ok := results.add("PROJ-3")
+	ok.ID = "issue-3"
+	ok.URL = "https://plane.example.com/ws/projects/p/issues/issue-3"
+	results.record("issue-3", "comment", nil)
+
+	return results
+}
+
+func TestResult(t *testing.T) {
+	results := testResults()
+
+	if got := len(results.Issues); got != 3 {
+		t.Fatalf("Expected 3 issues, got %d", got)
+	}
+	if results.add("PROJ-1") != results.Issues[0] {
+		t.Error("Expected add to return the existing result for a known key")
+	}
+	if got := results.FailedCount(); got != 2 {
+		t.Errorf("Expected failed count 2, got %d", got)
+	}
+
+	// nil 结果不记录也不崩溃
+	// A nil result records nothing and doesn't panic
+	var none *Result
+	none.record("issue-1", "comment", nil)
+}
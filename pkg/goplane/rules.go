@@ -0,0 +1,141 @@
+package goplane
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"text/template"
+)
+
+// Rule fills unset operations of a run when every condition under "When"
+// matches the event; empty conditions match anything, and branch and tag
+// conditions support glob patterns. The operations are text/template
+// templates, e.g. "Released in {{tag}}".
+type Rule struct {
+	When struct {
+		Event  string `yaml:"event"`
+		Action string `yaml:"action"`
+		Branch string `yaml:"branch"`
+		Tag    string `yaml:"tag"`
+	} `yaml:"when"`
+	State    string   `yaml:"state"`
+	Comment  string   `yaml:"comment"`
+	Assignee string   `yaml:"assignee"`
+	Labels   []string `yaml:"labels"`
+	Module   string   `yaml:"module"`
+}
+
+// EventContext describes the CI event that triggered a run, used to match
+// rules and render their templates.
+type EventContext struct {
+	Event      string
+	Action     string
+	Branch     string
+	Tag        string
+	SHA        string
+	Repository string
+	// RepositoryURL is the web URL of the repository, e.g.
+	// "https://github.com/org/repo".
+	RepositoryURL string
+	Actor         string
+}
+
+// CommitAuthor identifies the author of the referencing commits; worklogs
+// are recorded for the project member matching any of the fields.
+type CommitAuthor struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+// 判断规则是否匹配当前事件, 分支和标签支持通配符
+// Report whether the rule matches the current event; branch and tag support glob patterns
+func (r Rule) matches(ctx EventContext) bool {
+	return matchField(r.When.Event, ctx.Event) &&
+		matchField(r.When.Action, ctx.Action) &&
+		matchField(r.When.Branch, ctx.Branch) &&
+		matchField(r.When.Tag, ctx.Tag)
+}
+
+func matchField(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// 返回第一个匹配当前事件的规则
+// Return the first rule matching the current event
+func selectRule(rules []Rule, ctx EventContext) (Rule, bool) {
+	for _, r := range rules {
+		if r.matches(ctx) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// 应用规则, 只填充未设置的操作
+// Apply a rule, only filling operations that are not set
+func applyRule(config Config, r Rule, ctx EventContext) (Config, error) {
+	fields := []struct {
+		dst *string
+		src string
+	}{
+		{&config.ToState, r.State},
+		{&config.Comment, r.Comment},
+		{&config.Assignee, r.Assignee},
+		{&config.Module, r.Module},
+	}
+
+	for _, field := range fields {
+		if *field.dst != "" || field.src == "" {
+			continue
+		}
+		value, err := renderTemplate(field.src, ctx)
+		if err != nil {
+			return config, err
+		}
+		*field.dst = value
+	}
+
+	// 复制标签列表, 避免并发处理时共享底层数组
+	// Copy the label list so concurrent runs don't share the backing array
+	config.Labels = slices.Clone(config.Labels)
+	for _, label := range r.Labels {
+		value, err := renderTemplate(label, ctx)
+		if err != nil {
+			return config, err
+		}
+		config.Labels = append(config.Labels, value)
+	}
+
+	return config, nil
+}
+
+// 渲染规则中的模板, 支持 {{tag}} 和 {{.Tag}} 两种写法
+// Render a template from a rule, supporting both {{tag}} and {{.Tag}}
+func renderTemplate(text string, ctx EventContext) (string, error) {
+	funcs := template.FuncMap{
+		"event":      func() string { return ctx.Event },
+		"action":     func() string { return ctx.Action },
+		"branch":     func() string { return ctx.Branch },
+		"tag":        func() string { return ctx.Tag },
+		"sha":        func() string { return ctx.SHA },
+		"repository": func() string { return ctx.Repository },
+		"actor":      func() string { return ctx.Actor },
+	}
+
+	tmpl, err := template.New("rule").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf(msg("parse_template_failed"), err)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, ctx); err != nil {
+		return "", fmt.Errorf(msg("render_template_failed"), err)
+	}
+	return b.String(), nil
+}
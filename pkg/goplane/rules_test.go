@@ -0,0 +1,83 @@
+package goplane
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testRules = `
+rules:
+  - when:
+      event: push
+      branch: main
+    state: Done
+    labels: [released]
+  - when:
+      event: pull_request
+      action: opened
+    state: In Review
+    comment: "Review requested by {{actor}}"
+  - when:
+      tag: v*
+    module: "{{tag}}"
+`
+
+func TestSelectRule(t *testing.T) {
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal([]byte(testRules), &file); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		ctx    EventContext
+		config Config
+		want   Config
+	}{
+		{
+			name: "push to main",
+			ctx:  EventContext{Event: "push", Branch: "main"},
+			want: Config{ToState: "Done", Labels: []string{"released"}},
+		},
+		{
+			name: "pull request opened",
+			ctx:  EventContext{Event: "pull_request", Action: "opened", Actor: "octocat"},
+			want: Config{ToState: "In Review", Comment: "Review requested by octocat"},
+		},
+		{
+			name:   "env state is kept",
+			ctx:    EventContext{Event: "pull_request", Action: "opened", Actor: "octocat"},
+			config: Config{ToState: "Testing"},
+			want:   Config{ToState: "Testing", Comment: "Review requested by octocat"},
+		},
+		{
+			name: "tag",
+			ctx:  EventContext{Event: "push", Tag: "v1.2.0"},
+			want: Config{Module: "v1.2.0"},
+		},
+		{
+			name: "no match",
+			ctx:  EventContext{Event: "push", Branch: "feature/x"},
+			want: Config{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config
+			var err error
+			if r, ok := selectRule(file.Rules, tt.ctx); ok {
+				if got, err = applyRule(got, r, tt.ctx); err != nil {
+					t.Fatalf("applyRule() error = %v", err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
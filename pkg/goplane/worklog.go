@@ -0,0 +1,218 @@
+package goplane
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GeekWorkCode/plane-api-go/api"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// 工作时间换算: 与 Jira smart commits 一致, 1d = 8h, 1w = 5d
+// Working time conversion, same as Jira smart commits: 1d = 8h, 1w = 5d
+const (
+	minutesPerHour = 60
+	minutesPerDay  = 8 * minutesPerHour
+	minutesPerWeek = 5 * minutesPerDay
+)
+
+var (
+	// 匹配 "#time 1h 30m 可选说明" 指令
+	// Match "#time 1h 30m optional comment" directives
+	timeDirectiveRegex = regexp.MustCompile(`#time\s+((?:\d+[wdhm]\s*)+)([^#\n]*)`)
+
+	// 匹配时长中的单个部分, 例如 "1h"
+	// Match a single part of a duration, e.g. "1h"
+	durationPartRegex = regexp.MustCompile(`(\d+)([wdhm])`)
+)
+
+// 时间记录指令
+// Time tracking directive
+type timeDirective struct {
+	minutes int
+	comment string
+}
+
+// 将 "1w 2d 3h 30m" 格式的时长解析为分钟
+// Parse a duration in "1w 2d 3h 30m" format into minutes
+func parseWorkDuration(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	parts := durationPartRegex.FindAllStringSubmatch(s, -1)
+	if len(parts) == 0 || strings.TrimSpace(durationPartRegex.ReplaceAllString(s, "")) != "" {
+		return 0, errors.New(msg("invalid_duration", s))
+	}
+
+	total := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part[1])
+		if err != nil {
+			return 0, errors.New(msg("invalid_duration", s))
+		}
+		switch part[2] {
+		case "w":
+			total += n * minutesPerWeek
+		case "d":
+			total += n * minutesPerDay
+		case "h":
+			total += n * minutesPerHour
+		case "m":
+			total += n
+		}
+	}
+
+	return total, nil
+}
+
+// 格式化分钟数为 "1h 30m" 格式
+// Format minutes as "1h 30m"
+func formatWorkDuration(minutes int) string {
+	if minutes <= 0 {
+		return "0m"
+	}
+
+	var parts []string
+	if h := minutes / minutesPerHour; h > 0 {
+		parts = append(parts, strconv.Itoa(h)+"h")
+	}
+	if m := minutes % minutesPerHour; m > 0 {
+		parts = append(parts, strconv.Itoa(m)+"m")
+	}
+	return strings.Join(parts, " ")
+}
+
+// 从提交消息中解析时间指令, 指令作用于同一行中的所有issue key
+// Parse time directives from a commit message; a directive applies to every issue key on the same line
+func parseTimeDirectives(ref string) map[string][]timeDirective {
+	result := make(map[string][]timeDirective)
+
+	for _, line := range strings.Split(ref, "\n") {
+		keys := IssueKeyPattern.FindAllString(line, -1)
+		if len(keys) == 0 {
+			continue
+		}
+
+		for _, match := range timeDirectiveRegex.FindAllStringSubmatch(line, -1) {
+			minutes, err := parseWorkDuration(match[1])
+			if err != nil || minutes == 0 {
+				continue
+			}
+			directive := timeDirective{
+				minutes: minutes,
+				comment: strings.TrimSpace(match[2]),
+			}
+			for _, key := range keys {
+				result[key] = append(result[key], directive)
+			}
+		}
+	}
+
+	return result
+}
+
+// 记录工时, 如果 Plane 实例不支持工时记录, 则以结构化评论代替
+// Record worklogs, falling back to a structured comment when the Plane instance doesn't support worklogs
+func processWorklogs(ctx context.Context, config Config, issues []models.Issue, directives []timeDirective, results *Result) {
+	author := config.Author
+	forEach(ctx, config.Concurrency, len(issues), func(i int) {
+		issue := issues[i]
+		memberID := findMemberID(config, issue.Project, author)
+
+		for _, directive := range directives {
+			start := time.Now()
+			attrs := []any{"time", formatWorkDuration(directive.minutes), "author", author.Name}
+
+			description := directive.comment
+			if author.Name != "" {
+				description = strings.TrimSpace(description + " (" + author.Name + ")")
+			}
+
+			worklogReq := &api.WorklogCreateRequest{
+				Description: description,
+				Duration:    directive.minutes,
+			}
+
+			_, err := config.Client.CreateWorklog(config.WorkspaceSlug, issue.Project, issue.ID, worklogReq)
+			if err == nil {
+				logOperation("worklog", issue, start, nil, attrs...)
+				results.record(issue.ID, "worklog", nil)
+				continue
+			}
+
+			// 其它错误时服务器可能已保存工时, 不能再以评论重复记录
+			// On other errors the server may have stored the worklog already, so it mustn't be recorded again as a comment
+			if !worklogsUnsupported(err) {
+				logOperation("worklog", issue, start, err, attrs...)
+				results.record(issue.ID, "worklog", err)
+				continue
+			}
+			slog.Debug(msg("log.worklog_fallback"), "issue", issue.ID, "err", err)
+
+			commentReq := &api.CommentRequest{
+				CommentHTML: worklogCommentHTML(directive, author),
+				CreatedBy:   memberID,
+			}
+			_, err = config.Client.CreateComment(config.WorkspaceSlug, issue.Project, issue.ID, commentReq)
+			logOperation("worklog_comment", issue, start, err, attrs...)
+			results.record(issue.ID, "worklog_comment", err)
+		}
+	})
+}
+
+// 是否是 Plane 实例不支持工时记录的错误, 即接口返回 404 或 405
+// Whether err means the Plane instance doesn't support worklogs, i.e. the endpoint returned 404 or 405
+func worklogsUnsupported(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusMethodNotAllowed)
+}
+
+// 生成工时评论的HTML
+// Build the HTML of a worklog comment
+func worklogCommentHTML(directive timeDirective, author CommitAuthor) string {
+	var b strings.Builder
+	b.WriteString("<p><strong>" + html.EscapeString(msg("worklog.time_logged")) + "</strong> ")
+	b.WriteString(formatWorkDuration(directive.minutes))
+	if author.Name != "" {
+		b.WriteString(" " + msg("worklog.by", html.EscapeString(author.Name)))
+	}
+	b.WriteString("</p>")
+	if directive.comment != "" {
+		b.WriteString("<p>")
+		b.WriteString(html.EscapeString(directive.comment))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+// 根据提交作者查找项目成员ID, 找不到时返回空字符串
+// Find the project member ID of the commit author, returning an empty string if not found
+func findMemberID(config Config, projectID string, author CommitAuthor) string {
+	if author.Name == "" && author.Email == "" && author.Username == "" {
+		return ""
+	}
+
+	members, err := listMembers(config, projectID, nil)
+	if err != nil {
+		return ""
+	}
+
+	for _, member := range members {
+		user := member.Member
+		switch {
+		case author.Email != "" && strings.EqualFold(user.Email, author.Email),
+			author.Username != "" && strings.EqualFold(user.DisplayName, author.Username),
+			author.Name != "" && strings.EqualFold(user.DisplayName, author.Name),
+			author.Name != "" && strings.EqualFold(strings.TrimSpace(user.FirstName+" "+user.LastName), author.Name):
+			return user.ID
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,134 @@
+package goplane
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func TestParseWorkDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{name: "minutes", input: "45m", want: 45},
+		{name: "hours and minutes", input: "1h30m", want: 90},
+		{name: "space separated", input: "1h 30m", want: 90},
+		{name: "days", input: "2d", want: 2 * 8 * 60},
+		{name: "weeks", input: "1w", want: 5 * 8 * 60},
+		{name: "invalid unit", input: "3y", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWorkDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseWorkDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseWorkDuration(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWorkDuration(t *testing.T) {
+	tests := []struct {
+		minutes int
+		want    string
+	}{
+		{minutes: 0, want: "0m"},
+		{minutes: 45, want: "45m"},
+		{minutes: 60, want: "1h"},
+		{minutes: 90, want: "1h 30m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatWorkDuration(tt.minutes); got != tt.want {
+			t.Errorf("formatWorkDuration(%d) = %q, want %q", tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeDirectives(t *testing.T) {
+	ref := "PROJ-12 fix login #time 1h30m investigating session bug\n" +
+		"OPS-3 PROJ-14 #time 2h #comment deploy\n" +
+		"PROJ-15 no time here"
+
+	got := parseTimeDirectives(ref)
+	want := map[string][]timeDirective{
+		"PROJ-12": {{minutes: 90, comment: "investigating session bug"}},
+		"OPS-3":   {{minutes: 120}},
+		"PROJ-14": {{minutes: 120}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTimeDirectives() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProcessWorklogs(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		calls  []string
+		action ActionResult
+	}{
+		{
+			name:   "worklog",
+			calls:  []string{"worklog i1"},
+			action: ActionResult{Action: "worklog", Success: true},
+		},
+		{
+			name:   "unsupported",
+			err:    &StatusError{StatusCode: http.StatusNotFound, Err: errors.New("API error (Status: 404)")},
+			calls:  []string{"worklog i1", "comment i1 <p><strong>Time logged:</strong> 1h</p>"},
+			action: ActionResult{Action: "worklog_comment", Success: true},
+		},
+		{
+			name:   "method not allowed",
+			err:    &StatusError{StatusCode: http.StatusMethodNotAllowed, Err: errors.New("API error (Status: 405)")},
+			calls:  []string{"worklog i1", "comment i1 <p><strong>Time logged:</strong> 1h</p>"},
+			action: ActionResult{Action: "worklog_comment", Success: true},
+		},
+		{
+			name:   "server error",
+			err:    &StatusError{StatusCode: http.StatusBadGateway, Err: errors.New("API error (Status: 502)")},
+			calls:  []string{"worklog i1"},
+			action: ActionResult{Action: "worklog", Error: "API error (Status: 502)"},
+		},
+		{
+			name:   "timeout",
+			err:    errors.New("context deadline exceeded"),
+			calls:  []string{"worklog i1"},
+			action: ActionResult{Action: "worklog", Error: "context deadline exceeded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planeClient := newFakeClient()
+			planeClient.worklogErr = tt.err
+			config := Config{WorkspaceSlug: "ws", Client: planeClient}
+			results := &Result{}
+			results.add("PROJ-1").ID = "i1"
+
+			issues := []models.Issue{{ID: "i1", Project: "p1"}}
+			processWorklogs(context.Background(), config, issues, []timeDirective{{minutes: 60}}, results)
+
+			if !reflect.DeepEqual(planeClient.calls, tt.calls) {
+				t.Errorf("Expected calls %v, got %v", tt.calls, planeClient.calls)
+			}
+			if got := results.Issues[0].Actions; !reflect.DeepEqual(got, []ActionResult{tt.action}) {
+				t.Errorf("Expected actions %+v, got %+v", []ActionResult{tt.action}, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is used when no catalog matches the requested language,
+// and for messages missing from another catalog.
+const DefaultLanguage = "en"
+
+// Catalog holds the translated messages of a language. Each catalog is a
+// JSON file named after its language tag (e.g. "zh-CN.json") mapping message
+// IDs to fmt format strings; adding a language only requires a new file.
+type Catalog struct {
+	lang     string
+	messages map[string]string
+	fallback *Catalog
+}
+
+// Load reads the catalog best matching lang from the *.json files in fsys,
+// falling back to DefaultLanguage for unknown languages and missing messages.
+//
+// Parameters:
+//
+//	fsys - file system containing the catalog files.
+//	lang - language tag or locale, e.g. "zh-CN", "zh_CN.UTF-8" or "zh".
+//
+// Returns:
+//
+//	*Catalog - the catalog of the matched language.
+//	error - if a catalog file can't be read or parsed.
+func Load(fsys fs.FS, lang string) (*Catalog, error) {
+	languages, err := Languages(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	fallback, err := read(fsys, DefaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := Match(languages, lang)
+	if matched == DefaultLanguage {
+		return fallback, nil
+	}
+
+	catalog, err := read(fsys, matched)
+	if err != nil {
+		return nil, err
+	}
+	catalog.fallback = fallback
+	return catalog, nil
+}
+
+// Languages returns the sorted language tags of the catalogs in fsys.
+func Languages(fsys fs.FS) ([]string, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	languages := make([]string, 0, len(files))
+	for _, file := range files {
+		languages = append(languages, strings.TrimSuffix(path.Base(file), ".json"))
+	}
+	sort.Strings(languages)
+	return languages, nil
+}
+
+// Match returns the language from languages best matching the tag or locale
+// lang: an exact match first, then a match of the primary language subtag
+// ("zh-TW" matches "zh-CN"), and DefaultLanguage otherwise.
+func Match(languages []string, lang string) string {
+	lang = normalize(lang)
+	if lang == "" {
+		return DefaultLanguage
+	}
+
+	for _, candidate := range languages {
+		if strings.EqualFold(candidate, lang) {
+			return candidate
+		}
+	}
+
+	primary, _, _ := strings.Cut(lang, "-")
+	for _, candidate := range languages {
+		candidatePrimary, _, _ := strings.Cut(candidate, "-")
+		if strings.EqualFold(candidatePrimary, primary) {
+			return candidate
+		}
+	}
+
+	return DefaultLanguage
+}
+
+// Detect returns the preferred language from the environment: PLANE_LANG,
+// then the LC_ALL, LC_MESSAGES and LANG locale variables.
+func Detect(env func(string) string) string {
+	for _, key := range []string{"PLANE_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := normalize(env(key)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Language returns the language tag of the catalog.
+func (c *Catalog) Language() string {
+	return c.lang
+}
+
+// Has reports whether the catalog itself, without fallback, contains id.
+func (c *Catalog) Has(id string) bool {
+	_, ok := c.messages[id]
+	return ok
+}
+
+// IDs returns the sorted message IDs of the catalog, without fallback.
+func (c *Catalog) IDs() []string {
+	ids := make([]string, 0, len(c.messages))
+	for id := range c.messages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// T formats the message id with args. Messages missing from the catalog are
+// taken from the fallback catalog; unknown IDs are returned as they are.
+func (c *Catalog) T(id string, args ...any) string {
+	format := id
+	for catalog := c; catalog != nil; catalog = catalog.fallback {
+		if message, ok := catalog.messages[id]; ok {
+			format = message
+			break
+		}
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Layer returns a copy of c that falls back to base for messages missing
+// from c and its fallback, e.g. to layer a program's messages on those of a
+// library it uses. Neither catalog is changed.
+func (c *Catalog) Layer(base *Catalog) *Catalog {
+	layered := *c
+	if c.fallback != nil {
+		layered.fallback = c.fallback.Layer(base)
+	} else {
+		layered.fallback = base
+	}
+	return &layered
+}
+
+func read(fsys fs.FS, lang string) (*Catalog, error) {
+	data, err := fs.ReadFile(fsys, lang+".json")
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]string)
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("parse catalog %s: %w", lang, err)
+	}
+	return &Catalog{lang: lang, messages: messages}, nil
+}
+
+// normalize converts a locale such as "zh_CN.UTF-8" into a language tag
+// such as "zh-CN"; the C and POSIX locales yield an empty string.
+func normalize(lang string) string {
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang = strings.ReplaceAll(strings.TrimSpace(lang), "_", "-")
+	if lang == "C" || lang == "POSIX" {
+		return ""
+	}
+	return lang
+}
@@ -0,0 +1,144 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+var testCatalogs = fstest.MapFS{
+	"en.json":    {Data: []byte(`{"hello": "Hello %s", "bye": "Bye"}`)},
+	"zh-CN.json": {Data: []byte(`{"hello": "你好 %s"}`)},
+	"de.json":    {Data: []byte(`{"hello": "Hallo %s"}`)},
+}
+
+func TestMatch(t *testing.T) {
+	languages := []string{"de", "en", "zh-CN"}
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"", "en"},
+		{"zh-CN", "zh-CN"},
+		{"zh_CN.UTF-8", "zh-CN"},
+		{"zh-cn", "zh-CN"},
+		{"zh", "zh-CN"},
+		{"zh-TW", "zh-CN"},
+		{"de_DE@euro", "de"},
+		{"fr-FR", "en"},
+		{"C", "en"},
+		{"POSIX", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if got := Match(languages, tt.lang); got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"none", nil, ""},
+		{"PLANE_LANG wins", map[string]string{"PLANE_LANG": "zh-CN", "LANG": "de_DE.UTF-8"}, "zh-CN"},
+		{"LC_ALL before LANG", map[string]string{"LC_ALL": "de_DE.UTF-8", "LANG": "zh_CN.UTF-8"}, "de-DE"},
+		{"LANG", map[string]string{"LANG": "zh_CN.UTF-8"}, "zh-CN"},
+		{"C locale is skipped", map[string]string{"LC_ALL": "C", "LANG": "zh_CN.UTF-8"}, "zh-CN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(func(key string) string { return tt.env[key] }); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	tests := []struct {
+		lang     string
+		wantLang string
+		id       string
+		args     []any
+		want     string
+	}{
+		{"en", "en", "hello", []any{"Alice"}, "Hello Alice"},
+		{"zh_CN.UTF-8", "zh-CN", "hello", []any{"Alice"}, "你好 Alice"},
+		{"zh-CN", "zh-CN", "bye", nil, "Bye"},
+		{"de", "de", "unknown", nil, "unknown"},
+		{"fr", "en", "hello", []any{"Alice"}, "Hello Alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang+"/"+tt.id, func(t *testing.T) {
+			catalog, err := Load(testCatalogs, tt.lang)
+			if err != nil {
+				t.Fatalf("Load(%q) error = %v", tt.lang, err)
+			}
+			if catalog.Language() != tt.wantLang {
+				t.Errorf("Expected language %q, got %q", tt.wantLang, catalog.Language())
+			}
+			if got := catalog.T(tt.id, tt.args...); got != tt.want {
+				t.Errorf("T(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayer(t *testing.T) {
+	base, err := Load(fstest.MapFS{
+		"en.json":    {Data: []byte(`{"hello": "Hi %s", "error": "Error"}`)},
+		"zh-CN.json": {Data: []byte(`{"error": "错误"}`)},
+	}, "zh-CN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := Load(testCatalogs, "zh-CN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	layered := catalog.Layer(base)
+
+	// 先查找自身及其回退目录, 再查找 base
+	// Look up the catalog and its fallback first, then base
+	tests := []struct {
+		id   string
+		args []any
+		want string
+	}{
+		{"hello", []any{"Alice"}, "你好 Alice"},
+		{"bye", nil, "Bye"},
+		{"error", nil, "错误"},
+		{"unknown", nil, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := layered.T(tt.id, tt.args...); got != tt.want {
+			t.Errorf("T(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+	if layered.Language() != "zh-CN" || layered.Has("error") {
+		t.Errorf("Expected the layered catalog to keep its own language and messages")
+	}
+	if catalog.T("error") != "error" {
+		t.Error("Expected Layer not to change the catalog")
+	}
+}
+
+func TestLoadInvalidCatalog(t *testing.T) {
+	catalogs := fstest.MapFS{
+		"en.json": {Data: []byte(`{"hello": "Hello"}`)},
+		"xx.json": {Data: []byte(`not json`)},
+	}
+	if _, err := Load(catalogs, "xx"); err == nil {
+		t.Error("Expected an error for an invalid catalog")
+	}
+	if _, err := Load(fstest.MapFS{}, "en"); err == nil {
+		t.Error("Expected an error without a default catalog")
+	}
+}
@@ -0,0 +1,158 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HTML renders the document as HTML for Plane comments and descriptions.
+// Text is written as is, without escaping, so Markdown may contain HTML.
+//
+// Returns:
+//
+//	string - the HTML.
+func (d *Document) HTML() string {
+	var b strings.Builder
+	newlines := 0
+	// 列表后总有一个换行, 它代替下一个块前的换行
+	// A list is always followed by a newline, which takes the place of the one before the next block
+	afterList := false
+
+	// 输出待写的换行, 空行即连续两个换行, 转换为 <br><br>
+	// Write the pending newlines, converting pairs of them, i.e. blank lines, to <br><br>
+	flush := func() {
+		b.WriteString(strings.Repeat("<br><br>", newlines/2))
+		if newlines%2 == 1 {
+			b.WriteString("\n")
+		}
+		newlines = 0
+	}
+
+	for i, block := range d.Blocks {
+		if i > 0 && !afterList {
+			newlines++
+		}
+		afterList = false
+
+		switch block := block.(type) {
+		case *BlankLine:
+			continue
+		case *List:
+			flush()
+			writeHTMLList(&b, block, 0)
+			newlines = 1
+			afterList = true
+			continue
+		}
+
+		flush()
+		switch block := block.(type) {
+		case *Heading:
+			level := strconv.Itoa(block.Level)
+			b.WriteString("<h" + level + ">" + inlineHTML(block.Content) + "</h" + level + ">")
+		case *Line:
+			b.WriteString(inlineHTML(block.Content))
+		case *CodeBlock:
+			b.WriteString("<pre><code")
+			if block.Language != "" {
+				b.WriteString(` class="language-` + block.Language + `"`)
+			}
+			b.WriteString(">" + block.Code + "</code></pre>")
+		case *Table:
+			writeHTMLTable(&b, block)
+		case *RawBlock:
+			b.WriteString(block.HTML)
+		}
+	}
+	flush()
+
+	return b.String()
+}
+
+// 输出列表, 不含结束标签后的换行; 以任务项开始的无序列表是 Plane 的任务列表
+// Write a list, without a newline after the end tag; unordered lists starting with a task item are Plane task lists
+func writeHTMLList(b *strings.Builder, list *List, depth int) {
+	pad := func(extra int) string { return strings.Repeat(" ", depth*4+extra) }
+
+	tag, end := "<ul>", "</ul>"
+	switch {
+	case list.Ordered && list.Start != 1:
+		tag, end = `<ol start="`+strconv.Itoa(list.Start)+`">`, "</ol>"
+	case list.Ordered:
+		tag, end = "<ol>", "</ol>"
+	case len(list.Items) > 0 && list.Items[0].Task:
+		tag = `<ul data-type="taskList">`
+	}
+
+	b.WriteString(pad(0) + tag + "\n")
+	for _, item := range list.Items {
+		b.WriteString(pad(2) + "<li")
+		if item.Task {
+			b.WriteString(` data-type="taskItem" data-checked="` + strconv.FormatBool(item.Checked) + `"`)
+		}
+		b.WriteString(">" + inlineHTML(item.Content))
+		if len(item.Lists) > 0 {
+			// 包含嵌套列表时, 结束标签单独成行
+			// With nested lists, the end tag goes on its own line
+			b.WriteString("\n")
+			for _, nested := range item.Lists {
+				writeHTMLList(b, nested, depth+1)
+				b.WriteString("\n")
+			}
+			b.WriteString(pad(2))
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString(pad(0) + end)
+}
+
+// 输出表格
+// Write a table
+func writeHTMLTable(b *strings.Builder, table *Table) {
+	b.WriteString("<table>\n  <thead>\n")
+	writeHTMLTableRow(b, "th", table.Header, table.Aligns)
+	b.WriteString("  </thead>\n  <tbody>\n")
+	for _, row := range table.Rows {
+		writeHTMLTableRow(b, "td", row, table.Aligns)
+	}
+	b.WriteString("  </tbody>\n</table>")
+}
+
+// 输出表格行
+// Write a table row
+func writeHTMLTableRow(b *strings.Builder, tag string, cells [][]Inline, aligns []string) {
+	b.WriteString("    <tr>\n")
+	for i, align := range aligns {
+		b.WriteString("      <" + tag)
+		if align != "" {
+			b.WriteString(` style="text-align: ` + align + `"`)
+		}
+		b.WriteString(">" + inlineHTML(cells[i]) + "</" + tag + ">\n")
+	}
+	b.WriteString("    </tr>\n")
+}
+
+// 将行内节点转换为 HTML
+// Convert inline nodes to HTML
+func inlineHTML(nodes []Inline) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			b.WriteString(n.Value)
+		case *Code:
+			b.WriteString("<code>" + n.Value + "</code>")
+		case *Strong:
+			b.WriteString("<strong>" + inlineHTML(n.Content) + "</strong>")
+		case *Emphasis:
+			b.WriteString("<em>" + inlineHTML(n.Content) + "</em>")
+		case *Link:
+			b.WriteString(`<a href="` + n.URL + `">` + inlineHTML(n.Content) + "</a>")
+		case *Mention:
+			b.WriteString(`<span class="mention">@` + n.Name + "</span>")
+		case *Raw:
+			b.WriteString(n.HTML)
+		}
+	}
+	return b.String()
+}
@@ -1,137 +1,90 @@
 package markdown
 
 import (
-	"bytes"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
-var (
-	// 匹配用户@提及
-	// Match user @mentions
-	mentionRegex = regexp.MustCompile(`@([a-zA-Z0-9_-]+)`)
+// Option configures a Renderer, or Parse and the To* conversion functions.
+type Option func(*options)
 
-	// 匹配加粗文本
-	// Match bold text
-	boldRegex = regexp.MustCompile(`\*\*(.*?)\*\*`)
-
-	// 匹配斜体文本
-	// Match italic text
-	italicRegex = regexp.MustCompile(`\*(.*?)\*|_(.*?)_`)
-
-	// 匹配链接
-	// Match links
-	linkRegex = regexp.MustCompile(`\[(.*?)\]\((.*?)\)`)
-
-	// 匹配代码块
-	// Match code blocks
-	codeBlockRegex = regexp.MustCompile("```([a-zA-Z0-9]*)\n([\\s\\S]*?)```")
+type options struct {
+	issueKeyPattern *regexp.Regexp
+	issueKey        func(key string) string
+	repositoryURL   string
+	inline          []inlineRule
+	blocks          []BlockRule
+}
 
-	// 匹配内联代码
-	// Match inline code
-	inlineCodeRegex = regexp.MustCompile("`([^`]+)`")
+// WithIssueKeys replaces the issue keys matching pattern, e.g. "PROJ-40",
+// with the HTML returned by render, such as a link to the issue. Keys in
+// code spans, code blocks and links are left alone, as are keys for which
+// render returns an empty string.
+//
+// Parameters:
+//
+//	pattern - the pattern matching issue keys.
+//	render - returns the HTML replacing a key, or empty to keep it as text.
+//
+// Returns:
+//
+//	Option - the option for NewRenderer.
+func WithIssueKeys(pattern *regexp.Regexp, render func(key string) string) Option {
+	return func(o *options) {
+		o.issueKeyPattern = pattern
+		o.issueKey = render
+	}
+}
 
-	// 匹配标题
-	// Match headers
-	headerRegex = regexp.MustCompile(`(?m)^(#{1,6})\s+(.*)$`)
+// WithReferences links GitHub and Gitea style references to the
+// repository at repositoryURL, e.g. "https://github.com/org/repo": "#482"
+// and "org/other#12" to issues, which both forges redirect to pull
+// requests where needed, and SHAs like "abc1234" to commits. References
+// in code spans, code blocks and links are left alone.
+//
+// Parameters:
+//
+//	repositoryURL - the web URL of the repository, or empty to disable linking.
+//
+// Returns:
+//
+//	Option - the option for NewRenderer.
+func WithReferences(repositoryURL string) Option {
+	return func(o *options) {
+		o.repositoryURL = strings.TrimRight(repositoryURL, "/")
+	}
+}
 
-	// 匹配无序列表
-	// Match unordered lists
-	listItemRegex = regexp.MustCompile(`(?m)^[ \t]*[-*+][ \t]+(.*)$`)
-)
+// Parse parses Markdown text into a document that can be rendered in
+// several formats. It's a shorthand for NewRenderer(opts...).Parse;
+// reuse a Renderer to parse many texts with the same options.
+//
+// Parameters:
+//
+//	markdown - the Markdown text.
+//	opts - options such as WithIssueKeys.
+//
+// Returns:
+//
+//	*Document - the parsed document.
+func Parse(markdown string, opts ...Option) *Document {
+	return NewRenderer(opts...).Parse(markdown)
+}
 
 // ToHTML 将 Markdown 文本转换为 HTML
 // ToHTML converts Markdown text to HTML
-func ToHTML(markdown string) string {
-	if markdown == "" {
-		return ""
-	}
-
-	html := markdown
-
-	// 处理代码块
-	// Process code blocks
-	html = codeBlockRegex.ReplaceAllStringFunc(html, func(match string) string {
-		submatches := codeBlockRegex.FindStringSubmatch(match)
-		lang := submatches[1]
-		code := submatches[2]
-
-		if lang == "" {
-			return "<pre><code>" + code + "</code></pre>"
-		}
-		return "<pre><code class=\"language-" + lang + "\">" + code + "</code></pre>"
-	})
-
-	// 处理内联代码
-	// Process inline code
-	html = inlineCodeRegex.ReplaceAllString(html, "<code>$1</code>")
-
-	// 处理标题
-	// Process headers
-	html = headerRegex.ReplaceAllStringFunc(html, func(match string) string {
-		submatches := headerRegex.FindStringSubmatch(match)
-		level := len(submatches[1])
-		text := submatches[2]
-
-		return "<h" + strconv.Itoa(level) + ">" + text + "</h" + strconv.Itoa(level) + ">"
-	})
-
-	// 处理加粗文本
-	// Process bold text
-	html = boldRegex.ReplaceAllString(html, "<strong>$1</strong>")
-
-	// 处理斜体文本
-	// Process italic text
-	html = italicRegex.ReplaceAllStringFunc(html, func(match string) string {
-		if strings.HasPrefix(match, "*") {
-			return "<em>" + match[1:len(match)-1] + "</em>"
-		}
-		return "<em>" + match[1:len(match)-1] + "</em>"
-	})
-
-	// 处理链接
-	// Process links
-	html = linkRegex.ReplaceAllString(html, "<a href=\"$2\">$1</a>")
-
-	// 处理无序列表
-	// Process unordered lists
-	if listItemRegex.MatchString(html) {
-		var b bytes.Buffer
-		lines := strings.Split(html, "\n")
-		inList := false
-
-		for _, line := range lines {
-			if listItemRegex.MatchString(line) {
-				if !inList {
-					b.WriteString("<ul>\n")
-					inList = true
-				}
-				matches := listItemRegex.FindStringSubmatch(line)
-				b.WriteString("  <li>" + matches[1] + "</li>\n")
-			} else {
-				if inList {
-					b.WriteString("</ul>\n")
-					inList = false
-				}
-				b.WriteString(line + "\n")
-			}
-		}
-
-		if inList {
-			b.WriteString("</ul>\n")
-		}
-
-		html = b.String()
-	}
-
-	// 处理用户@提及
-	// Process user @mentions
-	html = mentionRegex.ReplaceAllString(html, "<span class=\"mention\">@$1</span>")
+func ToHTML(markdown string, opts ...Option) string {
+	return NewRenderer(opts...).ToHTML(markdown)
+}
 
-	// 将换行符转换为<br>标签
-	// Convert newlines to <br> tags
-	html = strings.ReplaceAll(html, "\n\n", "<br><br>")
+// ToText 将 Markdown 文本转换为纯文本, 用于日志和终端输出
+// ToText converts Markdown text to plain text, for logs and terminal output
+func ToText(markdown string, opts ...Option) string {
+	return NewRenderer(opts...).ToText(markdown)
+}
 
-	return html
+// ToSlack 将 Markdown 文本转换为 Slack mrkdwn
+// ToSlack converts Markdown text to Slack mrkdwn
+func ToSlack(markdown string, opts ...Option) string {
+	return NewRenderer(opts...).ToSlack(markdown)
 }
@@ -1,6 +1,7 @@
 package markdown
 
 import (
+	"regexp"
 	"testing"
 )
 
@@ -35,6 +36,11 @@ func TestToHTML(t *testing.T) {
 			markdown: "This is _italic_ text.",
 			want:     "This is <em>italic</em> text.",
 		},
+		{
+			name:     "underscores inside words",
+			markdown: "Set snake_case_name, not _this_.",
+			want:     "Set snake_case_name, not <em>this</em>.",
+		},
 		{
 			name:     "link",
 			markdown: "Check out [this link](https://example.com).",
@@ -75,6 +81,56 @@ func TestToHTML(t *testing.T) {
 			markdown: "- Item 1\n- Item 2\n- Item 3",
 			want:     "<ul>\n  <li>Item 1</li>\n  <li>Item 2</li>\n  <li>Item 3</li>\n</ul>\n",
 		},
+		{
+			name:     "ordered list",
+			markdown: "1. Open the page\n2. Click save",
+			want:     "<ol>\n  <li>Open the page</li>\n  <li>Click save</li>\n</ol>\n",
+		},
+		{
+			name:     "ordered list with start",
+			markdown: "3) Third\n4) Fourth",
+			want:     "<ol start=\"3\">\n  <li>Third</li>\n  <li>Fourth</li>\n</ol>\n",
+		},
+		{
+			name:     "nested lists of mixed types",
+			markdown: "1. Step one\n   - detail a\n   - detail b\n2. Step two",
+			want:     "<ol>\n  <li>Step one\n    <ul>\n      <li>detail a</li>\n      <li>detail b</li>\n    </ul>\n  </li>\n  <li>Step two</li>\n</ol>\n",
+		},
+		{
+			name:     "nested list with tabs",
+			markdown: "- Parent\n\t- Child\n\t\t- Grandchild\n- Sibling",
+			want:     "<ul>\n  <li>Parent\n    <ul>\n      <li>Child\n        <ul>\n          <li>Grandchild</li>\n        </ul>\n      </li>\n    </ul>\n  </li>\n  <li>Sibling</li>\n</ul>\n",
+		},
+		{
+			name:     "list type change",
+			markdown: "- Bullet\n1. Number",
+			want:     "<ul>\n  <li>Bullet</li>\n</ul>\n<ol>\n  <li>Number</li>\n</ol>\n",
+		},
+		{
+			name:     "task list",
+			markdown: "Steps:\n- [x] done\n- [ ] todo",
+			want:     "Steps:\n<ul data-type=\"taskList\">\n  <li data-type=\"taskItem\" data-checked=\"true\">done</li>\n  <li data-type=\"taskItem\" data-checked=\"false\">todo</li>\n</ul>\n",
+		},
+		{
+			name:     "table with alignment",
+			markdown: "| Name | Time | Result |\n| :--- | ---: | :---: |\nparse | 12ms | ok\n| render | 3ms |",
+			want:     "<table>\n  <thead>\n    <tr>\n      <th style=\"text-align: left\">Name</th>\n      <th style=\"text-align: right\">Time</th>\n      <th style=\"text-align: center\">Result</th>\n    </tr>\n  </thead>\n  <tbody>\n    <tr>\n      <td style=\"text-align: left\">parse</td>\n      <td style=\"text-align: right\">12ms</td>\n      <td style=\"text-align: center\">ok</td>\n    </tr>\n    <tr>\n      <td style=\"text-align: left\">render</td>\n      <td style=\"text-align: right\">3ms</td>\n      <td style=\"text-align: center\"></td>\n    </tr>\n  </tbody>\n</table>",
+		},
+		{
+			name:     "table with escaped pipes and inline formatting",
+			markdown: "Results:\n| Case | Output |\n| --- | --- |\n| **or** | `a \\| b` |\n\nDone.",
+			want:     "Results:\n<table>\n  <thead>\n    <tr>\n      <th>Case</th>\n      <th>Output</th>\n    </tr>\n  </thead>\n  <tbody>\n    <tr>\n      <td><strong>or</strong></td>\n      <td><code>a | b</code></td>\n    </tr>\n  </tbody>\n</table><br><br>Done.",
+		},
+		{
+			name:     "pipes without a delimiter row",
+			markdown: "a | b\nc | d",
+			want:     "a | b\nc | d",
+		},
+		{
+			name:     "delimiter row with a different number of columns",
+			markdown: "a | b\n---",
+			want:     "a | b\n---",
+		},
 		{
 			name:     "mentions",
 			markdown: "Hello @user!",
@@ -101,3 +157,111 @@ func TestToHTML(t *testing.T) {
 		})
 	}
 }
+
+func TestToHTMLIssueKeys(t *testing.T) {
+	pattern := regexp.MustCompile(`[A-Z][A-Z0-9]+-[0-9]+`)
+	link := WithIssueKeys(pattern, func(key string) string {
+		if key == "GONE-1" {
+			return ""
+		}
+		return `<a href="https://plane.example.com/ws/browse/` + key + `/">` + key + `</a>`
+	})
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "text",
+			markdown: "duplicate of PROJ-40",
+			want:     `duplicate of <a href="https://plane.example.com/ws/browse/PROJ-40/">PROJ-40</a>`,
+		},
+		{
+			name:     "inline formatting and lists",
+			markdown: "- **PROJ-1** and PROJ-2",
+			want:     "<ul>\n  <li><strong><a href=\"https://plane.example.com/ws/browse/PROJ-1/\">PROJ-1</a></strong> and <a href=\"https://plane.example.com/ws/browse/PROJ-2/\">PROJ-2</a></li>\n</ul>\n",
+		},
+		{
+			name:     "code span",
+			markdown: "run `fix PROJ-3` for PROJ-4",
+			want:     `run <code>fix PROJ-3</code> for <a href="https://plane.example.com/ws/browse/PROJ-4/">PROJ-4</a>`,
+		},
+		{
+			name:     "code block",
+			markdown: "```\nif a < b { PROJ-5 }\n```",
+			want:     "<pre><code>if a < b { PROJ-5 }\n</code></pre>",
+		},
+		{
+			name:     "existing link",
+			markdown: "[see PROJ-6](https://example.com/PROJ-6)",
+			want:     `<a href="https://example.com/PROJ-6">see PROJ-6</a>`,
+		},
+		{
+			name:     "kept as text",
+			markdown: "GONE-1 stays",
+			want:     "GONE-1 stays",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.markdown, link); got != tt.want {
+				t.Errorf("ToHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToHTMLReferences(t *testing.T) {
+	references := WithReferences("https://github.com/org/repo/")
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "issue",
+			markdown: "Fixes #482.",
+			want:     `Fixes <a href="https://github.com/org/repo/issues/482">#482</a>.`,
+		},
+		{
+			name:     "other repository",
+			markdown: "See org/other#12 and #3",
+			want:     `See <a href="https://github.com/org/other/issues/12">org/other#12</a> and <a href="https://github.com/org/repo/issues/3">#3</a>`,
+		},
+		{
+			name:     "commit",
+			markdown: "Reverts abc1234, not 1234567 or deadbeef",
+			want:     `Reverts <a href="https://github.com/org/repo/commit/abc1234">abc1234</a>, not 1234567 or deadbeef`,
+		},
+		{
+			name:     "code and links",
+			markdown: "`git show abc1234` and [#9](https://example.com/#9)",
+			want:     `<code>git show abc1234</code> and <a href="https://example.com/#9">#9</a>`,
+		},
+		{
+			name:     "headers and entities",
+			markdown: "# 12 steps\n\nit&#39;s #7",
+			want:     `<h1>12 steps</h1><br><br>it&#39;s <a href="https://github.com/org/repo/issues/7">#7</a>`,
+		},
+		{
+			name:     "list",
+			markdown: "- #1\n- cafe123",
+			want:     "<ul>\n  <li><a href=\"https://github.com/org/repo/issues/1\">#1</a></li>\n  <li><a href=\"https://github.com/org/repo/commit/cafe123\">cafe123</a></li>\n</ul>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.markdown, references); got != tt.want {
+				t.Errorf("ToHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := ToHTML("Fixes #482", WithReferences("")); got != "Fixes #482" {
+		t.Errorf("Expected no links without a repository URL, got %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package markdown
+
+// Document is a parsed Markdown text, rendered by its HTML, Text and
+// Slack methods. Blocks are the lines of the text, with multi-line
+// constructs such as lists, tables and code blocks as a single block.
+type Document struct {
+	Blocks []Block
+}
+
+// Block is a block level node: *Heading, *Line, *BlankLine, *CodeBlock,
+// *List, *Table or *RawBlock.
+type Block interface {
+	block()
+}
+
+// Inline is an inline node: *Text, *Code, *Strong, *Emphasis, *Link,
+// *Mention or *Raw.
+type Inline interface {
+	inline()
+}
+
+// Heading is a "#" to "######" heading.
+type Heading struct {
+	Level   int
+	Content []Inline
+}
+
+// Line is a line of text.
+type Line struct {
+	Content []Inline
+}
+
+// BlankLine is an empty line, separating paragraphs.
+type BlankLine struct{}
+
+// CodeBlock is a fenced code block.
+type CodeBlock struct {
+	// Language is the language after the opening fence, if any.
+	Language string
+	// Code is the content, ending with a newline.
+	Code string
+}
+
+// List is an ordered or unordered list.
+type List struct {
+	Ordered bool
+	// Start is the number of the first item of an ordered list.
+	Start int
+	Items []*ListItem
+}
+
+// ListItem is an item of a list, with the lists nested in it.
+type ListItem struct {
+	// Task is set for task list items, "- [ ] todo" or "- [x] done".
+	Task    bool
+	Checked bool
+	Content []Inline
+	Lists   []*List
+}
+
+// Table is a GitHub-flavored pipe table.
+type Table struct {
+	// Aligns holds the alignment of each column: "left", "center",
+	// "right" or empty.
+	Aligns []string
+	Header [][]Inline
+	Rows   [][][]Inline
+}
+
+// RawBlock is a block with prepared output for each format, returned by
+// custom block rules.
+type RawBlock struct {
+	HTML string
+	Text string
+	// Slack is the Slack mrkdwn output, Text when empty.
+	Slack string
+}
+
+// Text is plain text.
+type Text struct {
+	Value string
+}
+
+// Code is a code span.
+type Code struct {
+	Value string
+}
+
+// Strong is bold text.
+type Strong struct {
+	Content []Inline
+}
+
+// Emphasis is italic text.
+type Emphasis struct {
+	Content []Inline
+}
+
+// Link is a link to URL.
+type Link struct {
+	URL     string
+	Content []Inline
+}
+
+// Mention is a user @mention.
+type Mention struct {
+	Name string
+}
+
+// Raw is inline content with prepared output for each format.
+type Raw struct {
+	HTML string
+	Text string
+	// Slack is the Slack mrkdwn output, Text when empty.
+	Slack string
+}
+
+func (*Heading) block()   {}
+func (*Line) block()      {}
+func (*BlankLine) block() {}
+func (*CodeBlock) block() {}
+func (*List) block()      {}
+func (*Table) block()     {}
+func (*RawBlock) block()  {}
+
+func (*Text) inline()     {}
+func (*Code) inline()     {}
+func (*Strong) inline()   {}
+func (*Emphasis) inline() {}
+func (*Link) inline()     {}
+func (*Mention) inline()  {}
+func (*Raw) inline()      {}
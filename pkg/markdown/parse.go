@@ -0,0 +1,453 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// 匹配用户@提及
+	// Match user @mentions
+	mentionRegex = regexp.MustCompile(`@([a-zA-Z0-9_-]+)`)
+
+	// 匹配加粗文本
+	// Match bold text
+	boldRegex = regexp.MustCompile(`\*\*(.*?)\*\*`)
+
+	// 匹配斜体文本, 单词中的 _ 不算, 如 snake_case 和 :white_check_mark:
+	// Match italic text, ignoring _ inside words such as snake_case and :white_check_mark:
+	italicRegex = regexp.MustCompile(`\*(.*?)\*|\b_(.*?)_\b`)
+
+	// 匹配链接
+	// Match links
+	linkRegex = regexp.MustCompile(`\[(.*?)\]\((.*?)\)`)
+
+	// 匹配代码块的开始行
+	// Match the opening line of a code block
+	codeFenceRegex = regexp.MustCompile("^```([a-zA-Z0-9]*)[ \t]*$")
+
+	// 匹配内联代码
+	// Match inline code
+	inlineCodeRegex = regexp.MustCompile("`([^`]+)`")
+
+	// 匹配标题
+	// Match headers
+	headerRegex = regexp.MustCompile(`^(#{1,6})[ \t]+(.*)$`)
+
+	// 匹配无序和有序列表项, 捕获缩进、标记和内容
+	// Match unordered and ordered list items, capturing the indentation, marker and content
+	listItemRegex = regexp.MustCompile(`^([ \t]*)([-*+]|[0-9]{1,9}[.)])[ \t]+(.*)$`)
+
+	// 匹配表格的分隔行, 如 | :--- | ---: |
+	// Match the delimiter row of a table, e.g. | :--- | ---: |
+	tableDelimiterRegex = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+
+	// 匹配其它仓库的 issue 或拉取请求引用, 如 org/repo#12
+	// Match issue or pull request references to other repositories, e.g. org/repo#12
+	crossReferenceRegex = regexp.MustCompile(`(^|[^\w/.-])([\w.-]+/[\w.-]+)#([0-9]+)\b`)
+
+	// 匹配本仓库的 issue 或拉取请求引用, 如 #482; 不匹配 HTML 实体 &#39;
+	// Match issue or pull request references to the repository, e.g. #482; doesn't match HTML entities like &#39;
+	referenceRegex = regexp.MustCompile(`(^|[^\w&/#])#([0-9]+)\b`)
+
+	// 匹配提交 SHA, 如 abc1234
+	// Match commit SHAs, e.g. abc1234
+	commitRegex = regexp.MustCompile(`(^|[^\w/.#@-])([0-9a-f]{7,40})\b`)
+
+	// 匹配任务列表项的复选框
+	// Match the checkbox of a task list item
+	taskItemRegex = regexp.MustCompile(`^\[([ xX])\][ \t]+(.*)$`)
+)
+
+// 行内规则: 把文本中 pattern 的匹配替换为 replace 返回的节点, 返回 nil 时保留原文
+// Inline rule: replaces the matches of pattern in text with the nodes returned by replace, keeping the match when it returns nil
+type inlineRule struct {
+	pattern *regexp.Regexp
+	replace func(m []string) []Inline
+	// 不处理链接文字
+	// Leave link text alone
+	skipLinks bool
+}
+
+// 默认的行内规则和选项添加的规则, 按应用顺序排列
+// The default inline rules and those added by options, in the order they apply
+func (o *options) inlineRules() []inlineRule {
+	rules := []inlineRule{
+		{pattern: inlineCodeRegex, replace: func(m []string) []Inline { return []Inline{&Code{Value: m[1]}} }},
+		{pattern: linkRegex, replace: func(m []string) []Inline {
+			return []Inline{&Link{URL: m[2], Content: []Inline{&Text{Value: m[1]}}}}
+		}},
+		{pattern: boldRegex, replace: func(m []string) []Inline { return []Inline{&Strong{Content: []Inline{&Text{Value: m[1]}}}} }},
+		{pattern: italicRegex, replace: func(m []string) []Inline {
+			return []Inline{&Emphasis{Content: []Inline{&Text{Value: m[1] + m[2]}}}}
+		}},
+	}
+	rules = append(rules, o.inline...)
+
+	if o.repositoryURL != "" {
+		rules = append(rules, referenceRules(o.repositoryURL)...)
+	}
+	if o.issueKeyPattern != nil {
+		render := o.issueKey
+		rules = append(rules, inlineRule{pattern: o.issueKeyPattern, skipLinks: true, replace: func(m []string) []Inline {
+			html := render(m[0])
+			if html == "" {
+				return nil
+			}
+			return []Inline{&Raw{HTML: html, Text: m[0]}}
+		}})
+	}
+
+	return append(rules, inlineRule{pattern: mentionRegex, replace: func(m []string) []Inline {
+		return []Inline{&Mention{Name: m[1]}}
+	}})
+}
+
+// 将 #123、org/repo#12 和提交 SHA 链接到仓库的规则
+// Rules linking #123, org/repo#12 and commit SHAs to the repository
+func referenceRules(repositoryURL string) []inlineRule {
+	// 仓库地址去掉 owner/repo 即为服务器地址
+	// The server URL is the repository URL without owner/repo
+	server := repositoryURL
+	for range 2 {
+		if i := strings.LastIndexByte(server, '/'); i >= 0 {
+			server = server[:i]
+		}
+	}
+
+	link := func(prefix, url, text string) []Inline {
+		return []Inline{&Text{Value: prefix}, &Link{URL: url, Content: []Inline{&Text{Value: text}}}}
+	}
+
+	return []inlineRule{
+		{pattern: crossReferenceRegex, skipLinks: true, replace: func(m []string) []Inline {
+			return link(m[1], server+"/"+m[2]+"/issues/"+m[3], m[2]+"#"+m[3])
+		}},
+		{pattern: referenceRegex, skipLinks: true, replace: func(m []string) []Inline {
+			return link(m[1], repositoryURL+"/issues/"+m[2], "#"+m[2])
+		}},
+		{pattern: commitRegex, skipLinks: true, replace: func(m []string) []Inline {
+			// 只有字母或只有数字的单词多半不是 SHA
+			// Words of only letters or only digits are unlikely to be SHAs
+			if !strings.ContainsAny(m[2], "0123456789") || !strings.ContainsAny(m[2], "abcdef") {
+				return nil
+			}
+			return link(m[1], repositoryURL+"/commit/"+m[2], m[2])
+		}},
+	}
+}
+
+type parser struct {
+	renderer *Renderer
+}
+
+// 解析块级结构
+// Parse the block structure
+func (p *parser) blocks(lines []string) []Block {
+	var blocks []Block
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if block, n := p.customBlock(lines[i:]); block != nil {
+			blocks = append(blocks, block)
+			i += n - 1
+			continue
+		}
+
+		if m := codeFenceRegex.FindStringSubmatch(line); m != nil {
+			if end := closingFence(lines, i+1); end >= 0 {
+				code := strings.Join(lines[i+1:end], "\n") + "\n"
+				if end == i+1 {
+					code = ""
+				}
+				blocks = append(blocks, &CodeBlock{Language: m[1], Code: code})
+				i = end
+				continue
+			}
+		}
+
+		if m := headerRegex.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, &Heading{Level: len(m[1]), Content: p.inline(m[2])})
+			continue
+		}
+
+		if table, n := p.table(lines[i:]); table != nil {
+			blocks = append(blocks, table)
+			i += n - 1
+			continue
+		}
+
+		if listItemRegex.MatchString(line) {
+			list, n := p.lists(lines[i:])
+			blocks = append(blocks, list...)
+			i += n - 1
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			blocks = append(blocks, &BlankLine{})
+			continue
+		}
+		blocks = append(blocks, &Line{Content: p.inline(line)})
+	}
+	return blocks
+}
+
+// 应用第一个匹配的自定义块规则, 返回块和使用的行数
+// Apply the first matching custom block rule, returning the block and the number of lines used
+func (p *parser) customBlock(lines []string) (Block, int) {
+	for _, rule := range p.renderer.blockRules {
+		if block, n := rule(p.renderer, lines); block != nil && n > 0 {
+			return block, min(n, len(lines))
+		}
+	}
+	return nil, 0
+}
+
+// 代码块结束行的位置, 没有时返回 -1
+// The position of the closing fence of a code block, or -1 if there is none
+func closingFence(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "```" {
+			return i
+		}
+	}
+	return -1
+}
+
+// 解析 GFM 管道表格: 表头行后跟分隔行, 之后的含 | 的行为数据行; 返回表格和使用的行数
+// Parse a GFM pipe table: a header row followed by a delimiter row, then the rows containing | as data rows; returns the table and the number of lines used
+func (p *parser) table(lines []string) (*Table, int) {
+	if len(lines) < 2 || !strings.Contains(lines[0], "|") || !tableDelimiterRegex.MatchString(lines[1]) {
+		return nil, 0
+	}
+
+	header := splitTableRow(lines[0])
+	aligns := tableAligns(splitTableRow(lines[1]))
+	if len(header) != len(aligns) {
+		return nil, 0
+	}
+
+	table := &Table{Aligns: aligns, Header: p.cells(header, len(aligns))}
+	n := 2
+	for ; n < len(lines) && strings.Contains(lines[n], "|") && strings.TrimSpace(lines[n]) != ""; n++ {
+		table.Rows = append(table.Rows, p.cells(splitTableRow(lines[n]), len(aligns)))
+	}
+	return table, n
+}
+
+// 解析单元格, 单元格数按列数补齐或截断
+// Parse the cells of a row, padding or truncating them to the number of columns
+func (p *parser) cells(cells []string, columns int) [][]Inline {
+	row := make([][]Inline, columns)
+	for i := range row {
+		if i < len(cells) {
+			row[i] = p.inline(cells[i])
+		}
+	}
+	return row
+}
+
+// 按未转义的 | 拆分表格行, 去掉首尾的 |, 并还原转义的 \|
+// Split a table row at unescaped | characters, dropping the outer ones and restoring escaped \|
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// 由分隔行得到每列的对齐方式
+// Get the alignment of each column from the delimiter row
+func tableAligns(delimiters []string) []string {
+	aligns := make([]string, len(delimiters))
+	for i, d := range delimiters {
+		switch left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":"); {
+		case left && right:
+			aligns[i] = "center"
+		case right:
+			aligns[i] = "right"
+		case left:
+			aligns[i] = "left"
+		}
+	}
+	return aligns
+}
+
+// 正在解析的列表
+// A list being parsed
+type openList struct {
+	indent int
+	list   *List
+}
+
+// 解析连续的列表项行, 缩进更深的项成为上一项的嵌套列表; 返回顶层列表和使用的行数
+// Parse consecutive list item lines, items indented deeper become a nested list of the previous item; returns the top level lists and the number of lines used
+func (p *parser) lists(lines []string) ([]Block, int) {
+	var top []Block
+	var stack []openList
+
+	// 开始新列表, 嵌套在上一层的最后一项中
+	// Start a new list, nested in the last item of the parent level
+	openNew := func(indent int, ordered bool, marker string) {
+		list := &List{Ordered: ordered}
+		if ordered {
+			list.Start, _ = strconv.Atoi(marker[:len(marker)-1])
+		}
+		if len(stack) == 0 {
+			top = append(top, list)
+		} else {
+			parent := stack[len(stack)-1].list
+			item := parent.Items[len(parent.Items)-1]
+			item.Lists = append(item.Lists, list)
+		}
+		stack = append(stack, openList{indent: indent, list: list})
+	}
+
+	n := 0
+	for ; n < len(lines); n++ {
+		m := listItemRegex.FindStringSubmatch(lines[n])
+		if m == nil {
+			break
+		}
+
+		indent := indentWidth(m[1])
+		marker := m[2]
+		ordered := marker[0] >= '0' && marker[0] <= '9'
+
+		// 缩进不超过上一层的项属于上一层
+		// Items indented no deeper than the parent level belong to that level
+		for len(stack) > 1 && indent <= stack[len(stack)-2].indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		switch {
+		case len(stack) == 0, indent > stack[len(stack)-1].indent:
+			openNew(indent, ordered, marker)
+		case stack[len(stack)-1].list.Ordered != ordered:
+			stack = stack[:len(stack)-1]
+			openNew(indent, ordered, marker)
+		}
+
+		list := stack[len(stack)-1].list
+		list.Items = append(list.Items, p.listItem(m[3]))
+	}
+
+	return top, n
+}
+
+// 解析列表项内容和任务复选框
+// Parse the content and task checkbox of a list item
+func (p *parser) listItem(content string) *ListItem {
+	if m := taskItemRegex.FindStringSubmatch(content); m != nil {
+		return &ListItem{Task: true, Checked: m[1] != " ", Content: p.inline(m[2])}
+	}
+	return &ListItem{Content: p.inline(content)}
+}
+
+// 缩进宽度, 制表符按 4 个空格计算
+// Indentation width, counting a tab as 4 spaces
+func indentWidth(s string) int {
+	return len(s) + strings.Count(s, "\t")*3
+}
+
+// 按顺序应用行内规则解析文本
+// Parse text by applying the inline rules in order
+func (p *parser) inline(text string) []Inline {
+	nodes := []Inline{&Text{Value: text}}
+	for _, rule := range p.renderer.inlineRules {
+		nodes = applyRule(nodes, rule)
+	}
+	return nodes
+}
+
+// 在文本节点中应用规则, 并进入加粗、斜体和链接 (除非规则跳过链接) 的内容
+// Apply a rule to text nodes, descending into bold, italic and, unless the rule skips them, link content
+func applyRule(nodes []Inline, rule inlineRule) []Inline {
+	var out []Inline
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			out = append(out, splitText(n.Value, rule)...)
+		case *Strong:
+			n.Content = applyRule(n.Content, rule)
+			out = append(out, n)
+		case *Emphasis:
+			n.Content = applyRule(n.Content, rule)
+			out = append(out, n)
+		case *Link:
+			if !rule.skipLinks {
+				n.Content = applyRule(n.Content, rule)
+			}
+			out = append(out, n)
+		default:
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// 按规则的匹配拆分文本
+// Split text at the matches of a rule
+func splitText(text string, rule inlineRule) []Inline {
+	var out []Inline
+	last := 0
+	for _, loc := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		replaced := rule.replace(m)
+		if replaced == nil {
+			continue
+		}
+		out = appendInline(out, &Text{Value: text[last:loc[0]]})
+		for _, node := range replaced {
+			out = appendInline(out, node)
+		}
+		last = loc[1]
+	}
+	if out == nil {
+		return []Inline{&Text{Value: text}}
+	}
+	return appendInline(out, &Text{Value: text[last:]})
+}
+
+// 追加行内节点, 合并相邻的文本并略过空文本
+// Append an inline node, merging adjacent text and skipping empty text
+func appendInline(nodes []Inline, node Inline) []Inline {
+	t, ok := node.(*Text)
+	if !ok {
+		return append(nodes, node)
+	}
+	if t.Value == "" {
+		return nodes
+	}
+	if len(nodes) > 0 {
+		if last, ok := nodes[len(nodes)-1].(*Text); ok {
+			nodes[len(nodes)-1] = &Text{Value: last.Value + t.Value}
+			return nodes
+		}
+	}
+	return append(nodes, t)
+}
@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := func(s string) []Inline { return []Inline{&Text{Value: s}} }
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     []Block
+	}{
+		{
+			name:     "empty string",
+			markdown: "",
+			want:     nil,
+		},
+		{
+			name:     "heading and paragraphs",
+			markdown: "## Title\n\nSome **bold** text",
+			want: []Block{
+				&Heading{Level: 2, Content: text("Title")},
+				&BlankLine{},
+				&Line{Content: []Inline{&Text{Value: "Some "}, &Strong{Content: text("bold")}, &Text{Value: " text"}}},
+			},
+		},
+		{
+			name:     "code block",
+			markdown: "```go\nfmt.Println(\"**not bold**\")\n```",
+			want:     []Block{&CodeBlock{Language: "go", Code: "fmt.Println(\"**not bold**\")\n"}},
+		},
+		{
+			name:     "unclosed code fence",
+			markdown: "```go\ntext",
+			want:     []Block{&Line{Content: text("```go")}, &Line{Content: text("text")}},
+		},
+		{
+			name:     "nested task list",
+			markdown: "3. Release\n   - [x] tag\n   - [ ] notes",
+			want: []Block{&List{Ordered: true, Start: 3, Items: []*ListItem{{
+				Content: text("Release"),
+				Lists: []*List{{Items: []*ListItem{
+					{Task: true, Checked: true, Content: text("tag")},
+					{Task: true, Content: text("notes")},
+				}}},
+			}}}},
+		},
+		{
+			name:     "table",
+			markdown: "| Key | Value |\n| :-- | --- |\n| `a` |",
+			want: []Block{&Table{
+				Aligns: []string{"left", ""},
+				Header: [][]Inline{text("Key"), text("Value")},
+				Rows:   [][][]Inline{{{&Code{Value: "a"}}, nil}},
+			}},
+		},
+		{
+			name:     "link with formatting and mention",
+			markdown: "[**docs**](https://example.com) by @alice",
+			want: []Block{&Line{Content: []Inline{
+				&Link{URL: "https://example.com", Content: []Inline{&Strong{Content: text("docs")}}},
+				&Text{Value: " by "},
+				&Mention{Name: "alice"},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.markdown).Blocks
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	doc := Parse("PROJ-1 fixes #2",
+		WithReferences("https://github.com/org/repo"),
+		WithIssueKeys(regexp.MustCompile(`PROJ-[0-9]+`), func(key string) string { return "<b>" + key + "</b>" }),
+	)
+
+	want := []Block{&Line{Content: []Inline{
+		&Raw{HTML: "<b>PROJ-1</b>", Text: "PROJ-1"},
+		&Text{Value: " fixes "},
+		&Link{URL: "https://github.com/org/repo/issues/2", Content: []Inline{&Text{Value: "#2"}}},
+	}}}
+	if !reflect.DeepEqual(doc.Blocks, want) {
+		t.Errorf("Parse() = %#v, want %#v", doc.Blocks, want)
+	}
+}
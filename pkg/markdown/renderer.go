@@ -0,0 +1,109 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Renderer parses and renders Markdown with a fixed set of options,
+// including custom inline and block rules registered with WithInlineRule
+// and WithBlockRule. It is safe for concurrent use.
+type Renderer struct {
+	inlineRules []inlineRule
+	blockRules  []BlockRule
+}
+
+// BlockRule parses a custom block, such as an admonition, at the start of
+// lines. It returns the block, usually a *RawBlock, and the number of
+// lines it spans, or nil if lines don't start such a block. Content
+// inside the block can be parsed with the same rules by r.Parse.
+type BlockRule func(r *Renderer, lines []string) (Block, int)
+
+// NewRenderer returns a Renderer configured by opts.
+//
+// Parameters:
+//
+//	opts - options such as WithIssueKeys, WithInlineRule and WithBlockRule.
+//
+// Returns:
+//
+//	*Renderer - the renderer.
+func NewRenderer(opts ...Option) *Renderer {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Renderer{inlineRules: o.inlineRules(), blockRules: o.blocks}
+}
+
+// WithInlineRule replaces the matches of pattern, e.g. emoji shortcodes
+// or internal ticket links, with the inline nodes returned by replace,
+// such as a *Raw with the output for each format. Rules run in the order
+// they're added, after code spans, links, bold and italic text and before
+// references, issue keys and mentions. Text in code spans and links is
+// left alone.
+//
+// Parameters:
+//
+//	pattern - the pattern to replace.
+//	replace - returns the nodes replacing a match, given the match and its submatches, or nil to keep it as text.
+//
+// Returns:
+//
+//	Option - the option for NewRenderer.
+func WithInlineRule(pattern *regexp.Regexp, replace func(match []string) []Inline) Option {
+	return func(o *options) {
+		o.inline = append(o.inline, inlineRule{pattern: pattern, replace: replace, skipLinks: true})
+	}
+}
+
+// WithBlockRule adds a custom block rule, tried in the order added at
+// each line before the built-in blocks.
+//
+// Parameters:
+//
+//	rule - the block rule.
+//
+// Returns:
+//
+//	Option - the option for NewRenderer.
+func WithBlockRule(rule BlockRule) Option {
+	return func(o *options) {
+		o.blocks = append(o.blocks, rule)
+	}
+}
+
+// Parse parses Markdown text into a document that can be rendered in
+// several formats.
+//
+// Parameters:
+//
+//	markdown - the Markdown text.
+//
+// Returns:
+//
+//	*Document - the parsed document.
+func (r *Renderer) Parse(markdown string) *Document {
+	doc := &Document{}
+	if markdown == "" {
+		return doc
+	}
+	p := &parser{renderer: r}
+	doc.Blocks = p.blocks(strings.Split(markdown, "\n"))
+	return doc
+}
+
+// ToHTML converts Markdown text to HTML.
+func (r *Renderer) ToHTML(markdown string) string {
+	return r.Parse(markdown).HTML()
+}
+
+// ToText converts Markdown text to plain text.
+func (r *Renderer) ToText(markdown string) string {
+	return r.Parse(markdown).Text()
+}
+
+// ToSlack converts Markdown text to Slack mrkdwn.
+func (r *Renderer) ToSlack(markdown string) string {
+	return r.Parse(markdown).Slack()
+}
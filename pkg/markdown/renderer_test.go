@@ -0,0 +1,104 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRenderer(t *testing.T) {
+	emoji := map[string]string{"rocket": "🚀", "tada": "🎉", "white_check_mark": "✅"}
+	r := NewRenderer(
+		WithInlineRule(regexp.MustCompile(`:([a-z0-9_+-]+):`), func(m []string) []Inline {
+			if e, ok := emoji[m[1]]; ok {
+				return []Inline{&Text{Value: e}}
+			}
+			return nil
+		}),
+		WithInlineRule(regexp.MustCompile(`\bTICKET-[0-9]+\b`), func(m []string) []Inline {
+			return []Inline{&Link{URL: "https://tickets.example.com/" + m[0], Content: []Inline{&Text{Value: m[0]}}}}
+		}),
+		WithBlockRule(func(r *Renderer, lines []string) (Block, int) {
+			kind, ok := strings.CutPrefix(lines[0], ":::")
+			if !ok || kind == "" {
+				return nil, 0
+			}
+			for i := 1; i < len(lines); i++ {
+				if lines[i] == ":::" {
+					body := r.Parse(strings.Join(lines[1:i], "\n"))
+					return &RawBlock{
+						HTML:  `<div class="` + kind + `">` + body.HTML() + "</div>",
+						Text:  strings.ToUpper(kind) + ": " + body.Text(),
+						Slack: ":" + kind + ": " + body.Slack(),
+					}, i + 1
+				}
+			}
+			return nil, 0
+		}),
+	)
+
+	tests := []struct {
+		name     string
+		markdown string
+		html     string
+		text     string
+		slack    string
+	}{
+		{
+			name:     "inline rules",
+			markdown: "**Shipped** :rocket: TICKET-7, not :unknown:",
+			html:     `<strong>Shipped</strong> 🚀 <a href="https://tickets.example.com/TICKET-7">TICKET-7</a>, not :unknown:`,
+			text:     "Shipped 🚀 TICKET-7 (https://tickets.example.com/TICKET-7), not :unknown:",
+			slack:    "*Shipped* 🚀 <https://tickets.example.com/TICKET-7|TICKET-7>, not :unknown:",
+		},
+		{
+			name:     "inside bold, not in code or links",
+			markdown: "**:tada:** `TICKET-1` [TICKET-2](https://example.com)",
+			html:     `<strong>🎉</strong> <code>TICKET-1</code> <a href="https://example.com">TICKET-2</a>`,
+			text:     "🎉 TICKET-1 TICKET-2 (https://example.com)",
+			slack:    "*🎉* `TICKET-1` <https://example.com|TICKET-2>",
+		},
+		{
+			name:     "underscores in shortcodes",
+			markdown: "Checks :white_check_mark: _passed_",
+			html:     "Checks ✅ <em>passed</em>",
+			text:     "Checks ✅ passed",
+			slack:    "Checks ✅ _passed_",
+		},
+		{
+			name:     "block rule",
+			markdown: "Before\n:::warning\nRun **migrations** first\n:::\nAfter",
+			html:     "Before\n<div class=\"warning\">Run <strong>migrations</strong> first</div>\nAfter",
+			text:     "Before\nWARNING: Run migrations first\nAfter",
+			slack:    "Before\n:warning: Run *migrations* first\nAfter",
+		},
+		{
+			name:     "unclosed block",
+			markdown: ":::note\ntext",
+			html:     ":::note\ntext",
+			text:     ":::note\ntext",
+			slack:    ":::note\ntext",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.ToHTML(tt.markdown); got != tt.html {
+				t.Errorf("ToHTML() = %q, want %q", got, tt.html)
+			}
+			if got := r.ToText(tt.markdown); got != tt.text {
+				t.Errorf("ToText() = %q, want %q", got, tt.text)
+			}
+			if got := r.ToSlack(tt.markdown); got != tt.slack {
+				t.Errorf("ToSlack() = %q, want %q", got, tt.slack)
+			}
+		})
+	}
+}
+
+func TestRendererDefault(t *testing.T) {
+	markdown := "# Title\n\n- **a** @bob\n- [x] `b`"
+	if got, want := NewRenderer().ToHTML(markdown), ToHTML(markdown); got != want {
+		t.Errorf("NewRenderer().ToHTML() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 转义 Slack mrkdwn 中的控制字符
+// Escape the control characters of Slack mrkdwn
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Slack renders the document as Slack mrkdwn, for chat messages. Headings
+// become bold lines, tables are laid out in a code block and task list
+// items get ☑ or ☐ checkboxes.
+//
+// Returns:
+//
+//	string - the mrkdwn text.
+func (d *Document) Slack() string {
+	lines := make([]string, 0, len(d.Blocks))
+	for _, block := range d.Blocks {
+		switch block := block.(type) {
+		case *Heading:
+			lines = append(lines, "*"+inlineSlack(block.Content)+"*")
+		case *Line:
+			lines = append(lines, inlineSlack(block.Content))
+		case *BlankLine:
+			lines = append(lines, "")
+		case *CodeBlock:
+			lines = append(lines, "```\n"+slackEscaper.Replace(block.Code)+"```")
+		case *List:
+			lines = append(lines, slackList(block, 0)...)
+		case *Table:
+			lines = append(lines, "```\n"+slackEscaper.Replace(strings.Join(textTable(block), "\n"))+"\n```")
+		case *RawBlock:
+			if block.Slack != "" {
+				lines = append(lines, block.Slack)
+			} else {
+				lines = append(lines, slackEscaper.Replace(block.Text))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 列表的各行, 嵌套列表缩进四个空格
+// The lines of a list, indenting nested lists by four spaces
+func slackList(list *List, depth int) []string {
+	var lines []string
+	pad := strings.Repeat("    ", depth)
+	for i, item := range list.Items {
+		marker := "• "
+		if list.Ordered {
+			marker = strconv.Itoa(list.Start+i) + ". "
+		}
+		switch {
+		case item.Task && item.Checked:
+			marker = "☑ "
+		case item.Task:
+			marker = "☐ "
+		}
+		lines = append(lines, pad+marker+inlineSlack(item.Content))
+		for _, nested := range item.Lists {
+			lines = append(lines, slackList(nested, depth+1)...)
+		}
+	}
+	return lines
+}
+
+// 将行内节点转换为 Slack mrkdwn
+// Convert inline nodes to Slack mrkdwn
+func inlineSlack(nodes []Inline) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			b.WriteString(slackEscaper.Replace(n.Value))
+		case *Code:
+			b.WriteString("`" + slackEscaper.Replace(n.Value) + "`")
+		case *Strong:
+			b.WriteString("*" + inlineSlack(n.Content) + "*")
+		case *Emphasis:
+			b.WriteString("_" + inlineSlack(n.Content) + "_")
+		case *Link:
+			b.WriteString("<" + n.URL + "|" + inlineSlack(n.Content) + ">")
+		case *Mention:
+			b.WriteString("@" + n.Name)
+		case *Raw:
+			if n.Slack != "" {
+				b.WriteString(n.Slack)
+			} else {
+				b.WriteString(slackEscaper.Replace(n.Text))
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestToSlack(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "empty string",
+			markdown: "",
+			want:     "",
+		},
+		{
+			name:     "inline formatting",
+			markdown: "# Deploy\n\n**Done** in _3m_ with `make`, see [logs](https://ci.example.com/1)",
+			want:     "*Deploy*\n\n*Done* in _3m_ with `make`, see <https://ci.example.com/1|logs>",
+		},
+		{
+			name:     "escaping",
+			markdown: "a < b && `c > d`",
+			want:     "a &lt; b &amp;&amp; `c &gt; d`",
+		},
+		{
+			name:     "code block",
+			markdown: "```go\nif a < b {}\n```",
+			want:     "```\nif a &lt; b {}\n```",
+		},
+		{
+			name:     "nested lists",
+			markdown: "- Release\n  - [x] tag\n  - [ ] notes\n- Announce",
+			want:     "• Release\n    ☑ tag\n    ☐ notes\n• Announce",
+		},
+		{
+			name:     "ordered list",
+			markdown: "1. Open\n2. Save",
+			want:     "1. Open\n2. Save",
+		},
+		{
+			name:     "table",
+			markdown: "| Name | Time |\n| --- | ---: |\n| parse | 12ms |",
+			want:     "```\nName   Time\n-----  ----\nparse  12ms\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToSlack(tt.markdown); got != tt.want {
+				t.Errorf("ToSlack() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToSlackOptions(t *testing.T) {
+	got := ToSlack("PROJ-1 fixes #2",
+		WithReferences("https://github.com/org/repo"),
+		WithIssueKeys(regexp.MustCompile(`PROJ-[0-9]+`), func(key string) string { return "<b>" + key + "</b>" }),
+	)
+	if want := "PROJ-1 fixes <https://github.com/org/repo/issues/2|#2>"; got != want {
+		t.Errorf("ToSlack() = %q, want %q", got, want)
+	}
+}
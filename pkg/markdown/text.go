@@ -0,0 +1,143 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Text renders the document as plain text for logs and terminal output.
+// Formatting is dropped, links are written as "text (URL)" and lists and
+// tables are laid out with spaces.
+//
+// Returns:
+//
+//	string - the plain text.
+func (d *Document) Text() string {
+	lines := make([]string, 0, len(d.Blocks))
+	for _, block := range d.Blocks {
+		switch block := block.(type) {
+		case *Heading:
+			lines = append(lines, inlineText(block.Content))
+		case *Line:
+			lines = append(lines, inlineText(block.Content))
+		case *BlankLine:
+			lines = append(lines, "")
+		case *CodeBlock:
+			lines = append(lines, strings.TrimSuffix(block.Code, "\n"))
+		case *List:
+			lines = append(lines, textList(block, 0)...)
+		case *Table:
+			lines = append(lines, textTable(block)...)
+		case *RawBlock:
+			lines = append(lines, block.Text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 列表的各行, 嵌套列表缩进两个空格
+// The lines of a list, indenting nested lists by two spaces
+func textList(list *List, depth int) []string {
+	var lines []string
+	pad := strings.Repeat("  ", depth)
+	for i, item := range list.Items {
+		marker := "- "
+		if list.Ordered {
+			marker = strconv.Itoa(list.Start+i) + ". "
+		}
+		switch {
+		case item.Task && item.Checked:
+			marker += "[x] "
+		case item.Task:
+			marker += "[ ] "
+		}
+		lines = append(lines, pad+marker+inlineText(item.Content))
+		for _, nested := range item.Lists {
+			lines = append(lines, textList(nested, depth+1)...)
+		}
+	}
+	return lines
+}
+
+// 表格的各行, 各列按对齐方式补齐宽度, 表头下为分隔线
+// The lines of a table, padding the columns to their width as aligned, with a rule under the header
+func textTable(table *Table) []string {
+	rows := make([][]string, 0, len(table.Rows)+1)
+	for _, row := range append([][][]Inline{table.Header}, table.Rows...) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = inlineText(cell)
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(table.Aligns))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	format := func(row []string) string {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = alignText(cell, widths[i], table.Aligns[i])
+		}
+		return strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+
+	rules := make([]string, len(widths))
+	for i, width := range widths {
+		rules[i] = strings.Repeat("-", width)
+	}
+
+	lines := []string{format(rows[0]), strings.Join(rules, "  ")}
+	for _, row := range rows[1:] {
+		lines = append(lines, format(row))
+	}
+	return lines
+}
+
+// 按对齐方式用空格将文本补齐到 width
+// Pad text with spaces to width as aligned
+func alignText(text string, width int, align string) string {
+	gap := width - utf8.RuneCountInString(text)
+	switch align {
+	case "right":
+		return strings.Repeat(" ", gap) + text
+	case "center":
+		return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+	default:
+		return text + strings.Repeat(" ", gap)
+	}
+}
+
+// 将行内节点转换为纯文本
+// Convert inline nodes to plain text
+func inlineText(nodes []Inline) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			b.WriteString(n.Value)
+		case *Code:
+			b.WriteString(n.Value)
+		case *Strong:
+			b.WriteString(inlineText(n.Content))
+		case *Emphasis:
+			b.WriteString(inlineText(n.Content))
+		case *Link:
+			text := inlineText(n.Content)
+			b.WriteString(text)
+			if text != n.URL {
+				b.WriteString(" (" + n.URL + ")")
+			}
+		case *Mention:
+			b.WriteString("@" + n.Name)
+		case *Raw:
+			b.WriteString(n.Text)
+		}
+	}
+	return b.String()
+}
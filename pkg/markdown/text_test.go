@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestToText(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "empty string",
+			markdown: "",
+			want:     "",
+		},
+		{
+			name:     "inline formatting",
+			markdown: "# Deploy\n\n**Done** in _3m_ with `make`, see [logs](https://ci.example.com/1) or https://example.com",
+			want:     "Deploy\n\nDone in 3m with make, see logs (https://ci.example.com/1) or https://example.com",
+		},
+		{
+			name:     "link with the URL as text",
+			markdown: "[https://example.com](https://example.com)",
+			want:     "https://example.com",
+		},
+		{
+			name:     "mentions",
+			markdown: "Hello @user!",
+			want:     "Hello @user!",
+		},
+		{
+			name:     "code block",
+			markdown: "Run:\n```sh\nmake **test**\n```",
+			want:     "Run:\nmake **test**",
+		},
+		{
+			name:     "nested lists",
+			markdown: "2. Step two\n   - [x] done\n   - [ ] todo\n3. Step three",
+			want:     "2. Step two\n  - [x] done\n  - [ ] todo\n3. Step three",
+		},
+		{
+			name:     "table",
+			markdown: "| Name | Time | Result |\n| :--- | ---: | :---: |\n| parse | 12ms | ok |\n| render | 3ms |",
+			want:     "Name    Time  Result\n------  ----  ------\nparse   12ms    ok\nrender   3ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToText(tt.markdown); got != tt.want {
+				t.Errorf("ToText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToTextOptions(t *testing.T) {
+	got := ToText("PROJ-1 fixes #2",
+		WithReferences("https://github.com/org/repo"),
+		WithIssueKeys(regexp.MustCompile(`PROJ-[0-9]+`), func(key string) string { return "<b>" + key + "</b>" }),
+	)
+	if want := "PROJ-1 fixes #2 (https://github.com/org/repo/issues/2)"; got != want {
+		t.Errorf("ToText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,656 @@
+// Package planetest provides an in-process fake of the Plane v1 API for
+// tests, in the spirit of net/http/httptest.
+//
+// A Server serves the endpoints used by go-plane: projects, issues and the
+// sequence ID lookup, comments, states, labels, members, modules, issue
+// types and worklogs. Workspaces are seeded with fixtures, writes change the seeded
+// data, and every request is recorded so tests can assert on the calls made.
+package planetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+// APIPath is the path prefix of the API; clients use Server.BaseURL.
+const APIPath = "/api/v1"
+
+// Issue is an issue fixture. It adds the fields served by Plane but missing
+// from plane-api-go's models.Issue.
+type Issue struct {
+	models.Issue
+	SequenceID int        `json:"sequence_id"`
+	Labels     []string   `json:"labels"`
+	TypeID     string     `json:"type_id,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at"`
+}
+
+// IssueType is an issue type fixture; plane-api-go has no model for it.
+type IssueType struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Project string `json:"project"`
+}
+
+// Fixtures is the data of a workspace. Issues, states, labels, modules and
+// issue types belong to the project named by their Project field; members
+// belong to every project of the workspace.
+type Fixtures struct {
+	Projects   []models.Project
+	Issues     []Issue
+	States     []models.State
+	Labels     []models.Label
+	Modules    []models.Module
+	Members    []models.MemberUser
+	IssueTypes []IssueType
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	Method string
+	// Path is relative to APIPath, e.g. "/workspaces/ws/projects/".
+	Path string
+	Body []byte
+}
+
+// 工作区数据及运行中写入的评论、工时和模块issue
+// Workspace data, plus the comments, worklogs and module issues written while running
+type workspace struct {
+	Fixtures
+	comments     map[string][]models.Comment
+	worklogs     map[string][]models.Worklog
+	moduleIssues map[string][]string
+}
+
+// Server is a fake Plane API server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Token, when set, must be sent in the X-API-Key header of every request.
+	Token string
+
+	mu         sync.Mutex
+	workspaces map[string]*workspace
+	requests   []Request
+	nextID     int
+	mux        *http.ServeMux
+}
+
+// NewServer starts and returns a Server with no workspaces. The caller
+// should call Close when finished, to shut it down.
+//
+// Returns:
+//
+//	*Server - the started server.
+func NewServer() *Server {
+	s := &Server{workspaces: make(map[string]*workspace)}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects", s.listProjects)
+	s.mux.HandleFunc("GET /workspaces/{ws}/issues/{sequence}", s.getIssueBySequenceID)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/issues/{issue}", s.getIssue)
+	s.mux.HandleFunc("PATCH /workspaces/{ws}/projects/{project}/issues/{issue}", s.updateIssue)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/issues/{issue}/comments", s.listComments)
+	s.mux.HandleFunc("POST /workspaces/{ws}/projects/{project}/issues/{issue}/comments", s.createComment)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/issues/{issue}/worklogs", s.listWorklogs)
+	s.mux.HandleFunc("POST /workspaces/{ws}/projects/{project}/issues/{issue}/worklogs", s.createWorklog)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/states", s.listStates)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/labels", s.listLabels)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/members", s.listMembers)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/modules", s.listModules)
+	s.mux.HandleFunc("GET /workspaces/{ws}/projects/{project}/issue-types", s.listIssueTypes)
+	s.mux.HandleFunc("POST /workspaces/{ws}/projects/{project}/modules", s.createModule)
+	s.mux.HandleFunc("POST /workspaces/{ws}/projects/{project}/modules/{module}/module-issues", s.addModuleIssues)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the API base URL to configure clients with.
+func (s *Server) BaseURL() string {
+	return s.URL + APIPath
+}
+
+// Seed adds fixtures to a workspace, creating it if needed.
+//
+// Parameters:
+//
+//	workspaceSlug - the slug of the workspace.
+//	fixtures - the data to add.
+func (s *Server) Seed(workspaceSlug string, fixtures Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ws, ok := s.workspaces[workspaceSlug]
+	if !ok {
+		ws = &workspace{
+			comments:     make(map[string][]models.Comment),
+			worklogs:     make(map[string][]models.Worklog),
+			moduleIssues: make(map[string][]string),
+		}
+		s.workspaces[workspaceSlug] = ws
+	}
+
+	ws.Projects = append(ws.Projects, fixtures.Projects...)
+	ws.Issues = append(ws.Issues, fixtures.Issues...)
+	ws.States = append(ws.States, fixtures.States...)
+	ws.Labels = append(ws.Labels, fixtures.Labels...)
+	ws.Modules = append(ws.Modules, fixtures.Modules...)
+	ws.Members = append(ws.Members, fixtures.Members...)
+	ws.IssueTypes = append(ws.IssueTypes, fixtures.IssueTypes...)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Writes returns the requests received so far that weren't GET requests.
+func (s *Server) Writes() []Request {
+	var writes []Request
+	for _, r := range s.Requests() {
+		if r.Method != http.MethodGet {
+			writes = append(writes, r)
+		}
+	}
+	return writes
+}
+
+// Issue returns the current state of an issue.
+func (s *Server) Issue(workspaceSlug, issueID string) (Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ws, ok := s.workspaces[workspaceSlug]; ok {
+		if i := ws.issueIndex(issueID); i >= 0 {
+			return ws.Issues[i], true
+		}
+	}
+	return Issue{}, false
+}
+
+// Comments returns the comments created on an issue.
+func (s *Server) Comments(workspaceSlug, issueID string) []models.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ws, ok := s.workspaces[workspaceSlug]; ok {
+		return slices.Clone(ws.comments[issueID])
+	}
+	return nil
+}
+
+// Worklogs returns the worklogs created on an issue.
+func (s *Server) Worklogs(workspaceSlug, issueID string) []models.Worklog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ws, ok := s.workspaces[workspaceSlug]; ok {
+		return slices.Clone(ws.worklogs[issueID])
+	}
+	return nil
+}
+
+// Modules returns the modules of a project, including created ones.
+func (s *Server) Modules(workspaceSlug, projectID string) []models.Module {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ws, ok := s.workspaces[workspaceSlug]; ok {
+		return inProject(ws.Modules, projectID, func(m models.Module) string { return m.Project })
+	}
+	return nil
+}
+
+// ModuleIssues returns the IDs of the issues added to a module.
+func (s *Server) ModuleIssues(workspaceSlug, moduleID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ws, ok := s.workspaces[workspaceSlug]; ok {
+		return slices.Clone(ws.moduleIssues[moduleID])
+	}
+	return nil
+}
+
+// 记录请求并校验令牌, 去掉 API 前缀和结尾的斜杠后分发
+// Record the request and check the token, then dispatch without the API prefix and trailing slash
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, APIPath)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Body: body})
+	s.mu.Unlock()
+
+	if s.Token != "" && r.Header.Get("X-API-Key") != s.Token {
+		writeError(w, http.StatusUnauthorized, "Authentication credentials were not provided.")
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, APIPath+"/") {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	r.URL.Path = strings.TrimSuffix(path, "/")
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	s.mux.ServeHTTP(w, r)
+}
+
+// 查找请求的工作区和项目, 找不到时写入 404 并返回 false; 调用方需持有锁
+// Look up the workspace and project of the request, writing a 404 and returning false if not found; the caller must hold the lock
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*workspace, bool) {
+	ws, ok := s.workspaces[r.PathValue("ws")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Workspace not found.")
+		return nil, false
+	}
+
+	if projectID := r.PathValue("project"); projectID != "" {
+		if !slices.ContainsFunc(ws.Projects, func(p models.Project) bool { return p.ID == projectID }) {
+			writeError(w, http.StatusNotFound, "Project not found.")
+			return nil, false
+		}
+	}
+	return ws, true
+}
+
+// 与 lookup 相同, 并查找请求的issue
+// Same as lookup, also looking up the issue of the request
+func (s *Server) lookupIssue(w http.ResponseWriter, r *http.Request) (*workspace, int, bool) {
+	ws, ok := s.lookup(w, r)
+	if !ok {
+		return nil, -1, false
+	}
+
+	i := ws.issueIndex(r.PathValue("issue"))
+	if i < 0 || ws.Issues[i].Project != r.PathValue("project") {
+		writeError(w, http.StatusNotFound, "Issue not found.")
+		return nil, -1, false
+	}
+	return ws, i, true
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if ok {
+		writePage(w, ws.Projects)
+	}
+}
+
+// 按序列ID查找issue, 支持 "12" 和 "PROJ-12"; 只有数字时返回最先添加的匹配issue
+// Find an issue by sequence ID, accepting "12" and "PROJ-12"; with a bare number the first seeded match is returned
+func (s *Server) getIssueBySequenceID(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	sequence := r.PathValue("sequence")
+	identifier := ""
+	if prefix, n, found := strings.Cut(sequence, "-"); found {
+		identifier, sequence = prefix, n
+	}
+	n, err := strconv.Atoi(sequence)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Issue not found.")
+		return
+	}
+
+	for _, issue := range ws.Issues {
+		if issue.SequenceID != n {
+			continue
+		}
+		if identifier != "" && !strings.EqualFold(ws.projectIdentifier(issue.Project), identifier) {
+			continue
+		}
+		writeJSON(w, http.StatusOK, issue)
+		return
+	}
+	writeError(w, http.StatusNotFound, "Issue not found.")
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, i, ok := s.lookupIssue(w, r)
+	if ok {
+		writeJSON(w, http.StatusOK, ws.Issues[i])
+	}
+}
+
+// 更新issue, 只修改请求中出现的字段
+// Update an issue, changing only the fields present in the request
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, i, ok := s.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        *string   `json:"name"`
+		Description *string   `json:"description"`
+		State       *string   `json:"state"`
+		Priority    *string   `json:"priority"`
+		Assignees   *[]string `json:"assignees"`
+		Labels      *[]string `json:"labels"`
+	}
+	if err := json.Unmarshal(requestBody(r), &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	issue := &ws.Issues[i]
+	if req.State != nil && !slices.ContainsFunc(ws.States, func(st models.State) bool {
+		return st.ID == *req.State && st.Project == issue.Project
+	}) {
+		writeError(w, http.StatusBadRequest, "Invalid state.")
+		return
+	}
+
+	setIfPresent(&issue.Name, req.Name)
+	setIfPresent(&issue.Description, req.Description)
+	setIfPresent(&issue.State, req.State)
+	setIfPresent(&issue.Priority, req.Priority)
+	setIfPresent(&issue.Assignees, req.Assignees)
+	setIfPresent(&issue.Labels, req.Labels)
+	issue.UpdatedAt = time.Now()
+
+	writeJSON(w, http.StatusOK, issue)
+}
+
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, i, ok := s.lookupIssue(w, r)
+	if ok {
+		writePage(w, ws.comments[ws.Issues[i].ID])
+	}
+}
+
+// 创建评论, 指定 created_by 时填入对应成员
+// Create a comment, filling in the member given by created_by
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, i, ok := s.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CommentHTML string `json:"comment_html"`
+		CreatedBy   string `json:"created_by"`
+	}
+	if err := json.Unmarshal(requestBody(r), &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	issue := ws.Issues[i]
+	now := time.Now()
+	comment := models.Comment{
+		ID:          s.newID("comment"),
+		CommentHTML: req.CommentHTML,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		CreatedBy:   req.CreatedBy,
+		Project:     issue.Project,
+		Issue:       issue.ID,
+	}
+	if j := slices.IndexFunc(ws.Members, func(m models.MemberUser) bool { return m.ID == req.CreatedBy }); j >= 0 {
+		member := ws.Members[j]
+		comment.Member = &member
+	}
+
+	ws.comments[issue.ID] = append(ws.comments[issue.ID], comment)
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func (s *Server) listWorklogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, i, ok := s.lookupIssue(w, r)
+	if ok {
+		writeJSON(w, http.StatusOK, nonNil(ws.worklogs[ws.Issues[i].ID]))
+	}
+}
+
+func (s *Server) createWorklog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, i, ok := s.lookupIssue(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Description string `json:"description"`
+		Duration    int    `json:"duration"`
+	}
+	if err := json.Unmarshal(requestBody(r), &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	issue := ws.Issues[i]
+	now := time.Now()
+	worklog := models.Worklog{
+		ID:          s.newID("worklog"),
+		Description: req.Description,
+		Duration:    req.Duration,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ProjectID:   issue.Project,
+	}
+
+	ws.worklogs[issue.ID] = append(ws.worklogs[issue.ID], worklog)
+	writeJSON(w, http.StatusCreated, worklog)
+}
+
+func (s *Server) listStates(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if ok {
+		writePage(w, inProject(ws.States, r.PathValue("project"), func(st models.State) string { return st.Project }))
+	}
+}
+
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if ok {
+		writePage(w, inProject(ws.Labels, r.PathValue("project"), func(l models.Label) string { return l.Project }))
+	}
+}
+
+// 成员接口返回用户列表而不是分页结果
+// The members endpoint returns a list of users rather than a page
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if ok {
+		writeJSON(w, http.StatusOK, nonNil(ws.Members))
+	}
+}
+
+// issue类型接口返回列表而不是分页结果
+// The issue types endpoint returns a list rather than a page
+func (s *Server) listIssueTypes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if ok {
+		types := inProject(ws.IssueTypes, r.PathValue("project"), func(t IssueType) string { return t.Project })
+		writeJSON(w, http.StatusOK, nonNil(types))
+	}
+}
+
+// 模块接口返回列表而不是分页结果
+// The modules endpoint returns a list rather than a page
+func (s *Server) listModules(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if ok {
+		modules := inProject(ws.Modules, r.PathValue("project"), func(m models.Module) string { return m.Project })
+		writeJSON(w, http.StatusOK, nonNil(modules))
+	}
+}
+
+func (s *Server) createModule(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(requestBody(r), &req); err != nil || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "Name is required.")
+		return
+	}
+
+	now := time.Now()
+	module := models.Module{
+		ID:          s.newID("module"),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Project:     r.PathValue("project"),
+	}
+
+	ws.Modules = append(ws.Modules, module)
+	writeJSON(w, http.StatusCreated, module)
+}
+
+func (s *Server) addModuleIssues(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ws, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	moduleID := r.PathValue("module")
+	if !slices.ContainsFunc(ws.Modules, func(m models.Module) bool { return m.ID == moduleID }) {
+		writeError(w, http.StatusNotFound, "Module not found.")
+		return
+	}
+
+	var req struct {
+		Issues []string `json:"issues"`
+	}
+	if err := json.Unmarshal(requestBody(r), &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, issueID := range req.Issues {
+		if !slices.Contains(ws.moduleIssues[moduleID], issueID) {
+			ws.moduleIssues[moduleID] = append(ws.moduleIssues[moduleID], issueID)
+		}
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+// 生成新对象的ID, 调用方需持有锁
+// Generate the ID of a new object; the caller must hold the lock
+func (s *Server) newID(kind string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", kind, s.nextID)
+}
+
+func (ws *workspace) issueIndex(issueID string) int {
+	return slices.IndexFunc(ws.Issues, func(issue Issue) bool { return issue.ID == issueID })
+}
+
+func (ws *workspace) projectIdentifier(projectID string) string {
+	for _, project := range ws.Projects {
+		if project.ID == projectID {
+			return project.Identifier
+		}
+	}
+	return ""
+}
+
+// 返回属于指定项目的元素
+// Return the items belonging to a project
+func inProject[T any](items []T, projectID string, project func(T) string) []T {
+	var result []T
+	for _, item := range items {
+		if project(item) == projectID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func setIfPresent[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// nil 切片编码为 [] 而不是 null
+// Encode a nil slice as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func requestBody(r *http.Request) []byte {
+	body, _ := io.ReadAll(r.Body)
+	return body
+}
+
+// 以 Plane 的分页格式输出列表
+// Write a list in Plane's paginated format
+func writePage[T any](w http.ResponseWriter, items []T) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"results":           nonNil(items),
+		"count":             len(items),
+		"total_count":       len(items),
+		"next_page_results": false,
+		"prev_page_results": false,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
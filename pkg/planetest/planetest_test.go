@@ -0,0 +1,182 @@
+package planetest
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	plane "github.com/GeekWorkCode/plane-api-go"
+	"github.com/GeekWorkCode/plane-api-go/api"
+	planeclient "github.com/GeekWorkCode/plane-api-go/client"
+	"github.com/GeekWorkCode/plane-api-go/models"
+)
+
+func newTestServer(t *testing.T) (*Server, *plane.Plane) {
+	t.Helper()
+
+	server := NewServer()
+	t.Cleanup(server.Close)
+	server.Token = "secret"
+	server.Seed("ws", Fixtures{
+		Projects: []models.Project{{ID: "p1", Identifier: "PROJ"}, {ID: "p2", Identifier: "OPS"}},
+		Issues: []Issue{
+			{Issue: models.Issue{ID: "i1", Name: "Login page", Project: "p1", State: "s1"}, SequenceID: 1},
+			{Issue: models.Issue{ID: "i2", Name: "Disk full", Project: "p2"}, SequenceID: 1},
+		},
+		States:     []models.State{{ID: "s1", Name: "Todo", Project: "p1"}, {ID: "s2", Name: "Done", Project: "p1"}},
+		Labels:     []models.Label{{ID: "l1", Name: "bug", Project: "p1"}},
+		Members:    []models.MemberUser{{ID: "u1", DisplayName: "alice"}},
+		IssueTypes: []IssueType{{ID: "t1", Name: "Feature", Project: "p1"}},
+	})
+
+	client := plane.NewClient("secret")
+	client.SetBaseURL(server.BaseURL())
+	return server, client
+}
+
+func TestGetIssueBySequenceID(t *testing.T) {
+	_, client := newTestServer(t)
+
+	tests := []struct {
+		sequenceID string
+		want       string
+	}{
+		{sequenceID: "1", want: "i1"},
+		{sequenceID: "PROJ-1", want: "i1"},
+		{sequenceID: "ops-1", want: "i2"},
+		{sequenceID: "2", want: ""},
+		{sequenceID: "NOPE-1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sequenceID, func(t *testing.T) {
+			issue, err := client.Issues.GetBySequenceID("ws", tt.sequenceID)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", issue)
+				}
+				return
+			}
+			if err != nil || issue.ID != tt.want {
+				t.Errorf("GetBySequenceID(%s) = %+v, %v, want %s", tt.sequenceID, issue, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLists(t *testing.T) {
+	server, client := newTestServer(t)
+
+	projects, err := client.Projects.List("ws")
+	if err != nil || len(projects) != 2 {
+		t.Errorf("Projects.List() = %v, %v", projects, err)
+	}
+	states, err := client.States.List("ws", "p1")
+	if err != nil || len(states) != 2 {
+		t.Errorf("States.List() = %v, %v", states, err)
+	}
+	if states, err := client.States.List("ws", "p2"); err != nil || len(states) != 0 {
+		t.Errorf("Expected no states in p2, got %v, %v", states, err)
+	}
+	labels, err := client.Labels.List("ws", "p1")
+	if err != nil || len(labels) != 1 || labels[0].Name != "bug" {
+		t.Errorf("Labels.List() = %v, %v", labels, err)
+	}
+	members, err := client.Members.List("ws", "p2")
+	if err != nil || len(members) != 1 || members[0].Member.DisplayName != "alice" {
+		t.Errorf("Members.List() = %v, %v", members, err)
+	}
+	if _, err := client.Projects.List("other"); err == nil {
+		t.Error("Expected an error for an unknown workspace")
+	}
+	if _, err := client.States.List("ws", "p9"); err == nil {
+		t.Error("Expected an error for an unknown project")
+	}
+
+	// plane-api-go 未封装issue类型, 使用底层客户端
+	// plane-api-go doesn't wrap issue types, so use the low level client
+	raw := planeclient.NewClient("secret")
+	raw.SetBaseURL(server.BaseURL())
+	for project, want := range map[string]int{"p1": 1, "p2": 0} {
+		req, err := raw.NewRequest(http.MethodGet, "/workspaces/ws/projects/"+project+"/issue-types/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types []IssueType
+		if _, err := raw.Do(req, &types); err != nil || len(types) != want {
+			t.Errorf("Expected %d issue types in %s, got %v, %v", want, project, types, err)
+		}
+	}
+}
+
+func TestWrites(t *testing.T) {
+	server, client := newTestServer(t)
+
+	if _, err := client.Issues.Update("ws", "p1", "i1", &api.IssueUpdateRequest{StateName: "Done", AssigneeNames: []string{"alice"}}); err != nil {
+		t.Fatalf("Issues.Update() error = %v", err)
+	}
+	issue, _ := server.Issue("ws", "i1")
+	if issue.State != "s2" || !reflect.DeepEqual(issue.Assignees, []string{"u1"}) || issue.Name != "Login page" {
+		t.Errorf("Unexpected issue after update %+v", issue)
+	}
+	if _, err := client.Issues.Update("ws", "p1", "i1", &api.IssueUpdateRequest{State: "s9"}); err == nil {
+		t.Error("Expected an error for an unknown state")
+	}
+
+	if _, err := client.Comments.Create("ws", "p1", "i1", &api.CommentRequest{CommentHTML: "<p>Fixed</p>", CreatedBy: "u1"}); err != nil {
+		t.Fatalf("Comments.Create() error = %v", err)
+	}
+	comments := server.Comments("ws", "i1")
+	if len(comments) != 1 || comments[0].CommentHTML != "<p>Fixed</p>" || comments[0].Member == nil {
+		t.Errorf("Unexpected comments %+v", comments)
+	}
+
+	if _, err := client.Worklogs.Create("ws", "p1", "i1", &api.WorklogCreateRequest{Duration: 90}); err != nil {
+		t.Fatalf("Worklogs.Create() error = %v", err)
+	}
+	if worklogs := server.Worklogs("ws", "i1"); len(worklogs) != 1 || worklogs[0].Duration != 90 {
+		t.Errorf("Unexpected worklogs %+v", worklogs)
+	}
+
+	module, err := client.Modules.Create("ws", "p1", &api.ModuleCreateRequest{Name: "v1.0"})
+	if err != nil {
+		t.Fatalf("Modules.Create() error = %v", err)
+	}
+	if err := client.Modules.AddIssues("ws", "p1", module.ID, []string{"i1"}); err != nil {
+		t.Fatalf("Modules.AddIssues() error = %v", err)
+	}
+	if got := server.ModuleIssues("ws", module.ID); !reflect.DeepEqual(got, []string{"i1"}) {
+		t.Errorf("Expected module issues [i1], got %v", got)
+	}
+
+	var methods []string
+	for _, r := range server.Writes() {
+		methods = append(methods, r.Method+" "+r.Path)
+	}
+	want := []string{
+		"PATCH /workspaces/ws/projects/p1/issues/i1/",
+		"PATCH /workspaces/ws/projects/p1/issues/i1/",
+		"POST /workspaces/ws/projects/p1/issues/i1/comments/",
+		"POST /workspaces/ws/projects/p1/issues/i1/worklogs/",
+		"POST /workspaces/ws/projects/p1/modules/",
+		"POST /workspaces/ws/projects/p1/modules/" + module.ID + "/module-issues/",
+	}
+	if !reflect.DeepEqual(methods, want) {
+		t.Errorf("Expected writes %v, got %v", want, methods)
+	}
+}
+
+func TestToken(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	client := plane.NewClient("wrong")
+	client.SetBaseURL(server.BaseURL())
+	if _, err := client.Projects.List("ws"); err == nil {
+		t.Error("Expected an error for a wrong token")
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].Method != http.MethodGet || requests[0].Path != "/workspaces/ws/projects/" {
+		t.Errorf("Expected the rejected request to be recorded, got %+v", requests)
+	}
+}
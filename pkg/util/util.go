@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"os"
 	"strings"
 )
@@ -10,6 +11,9 @@ import (
 // where "<KEY>" is the input key converted to uppercase.
 // If the "INPUT_<KEY>" environment variable doesn't exist or is empty,
 // it returns the value of the "<KEY>" environment variable.
+// If neither is set, the value is read from the file named by "INPUT_<KEY>_FILE"
+// or "<KEY>_FILE", e.g. a Docker or Kubernetes secret, without the trailing newline.
+// Use LookupGlobalValue to tell an unreadable file from an unset value.
 //
 // Parameters:
 //
@@ -17,18 +21,50 @@ import (
 //
 // Returns:
 //
-//	string - the value of the environment variable.
+//	string - the value of the environment variable, or empty if the file can't be read.
 func GetGlobalValue(key string) string {
+	value, _ := LookupGlobalValue(key)
+	return value
+}
+
+// LookupGlobalValue returns the value of key like GetGlobalValue, along
+// with the error of reading the file named by "INPUT_<KEY>_FILE" or
+// "<KEY>_FILE".
+//
+// Parameters:
+//
+//	key - the key of the environment variable to retrieve.
+//
+// Returns:
+//
+//	string - the value of the environment variable, or empty if it's unset or the file can't be read.
+//	error - the error of reading the file, naming its variable, or nil.
+func LookupGlobalValue(key string) (string, error) {
 	key = strings.ToUpper(key) // Convert key to uppercase
 
 	// Check if there is an environment variable with the format "INPUT_<KEY>"
 	if value := os.Getenv("INPUT_" + key); value != "" {
-		return value // Return the value of the "INPUT_<KEY>" environment variable
+		return value, nil // Return the value of the "INPUT_<KEY>" environment variable
 	}
 
 	// If the "INPUT_<KEY>" environment variable doesn't exist or is empty,
 	// return the value of the "<KEY>" environment variable
-	return os.Getenv(key)
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+
+	// Otherwise read the file named by "INPUT_<KEY>_FILE" or "<KEY>_FILE"
+	for _, name := range []string{"INPUT_" + key + "_FILE", key + "_FILE"} {
+		if path := os.Getenv(name); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+			return strings.TrimRight(string(data), "\r\n"), nil
+		}
+	}
+
+	return "", nil
 }
 
 // ToBool converts a string to a boolean value.
@@ -45,3 +81,50 @@ func GetGlobalValue(key string) string {
 func ToBool(s string) bool {
 	return strings.ToLower(s) == "true" || s == "1"
 }
+
+// ToList splits a comma separated string into a list of trimmed,
+// non-empty values.
+//
+// Parameters:
+//
+//	s - the comma separated input string.
+//
+// Returns:
+//
+//	[]string - the values of the list, or nil if there are none.
+func ToList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// ToMap parses a comma separated list of key=value pairs, e.g.
+// "PROJ=ws-a,OPS=ws-b". Keys and values are trimmed, and pairs without a
+// key or value are skipped.
+//
+// Parameters:
+//
+//	s - the comma separated input string.
+//
+// Returns:
+//
+//	map[string]string - the pairs, or nil if there are none.
+func ToMap(s string) map[string]string {
+	var m map[string]string
+	for _, item := range ToList(s) {
+		key, value, _ := strings.Cut(item, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" || value == "" {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[key] = value
+	}
+	return m
+}
@@ -2,6 +2,9 @@ package util
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -69,6 +72,7 @@ func TestGetGlobalValue(t *testing.T) {
 		key         string
 		envVars     map[string]string
 		want        string
+		wantErr     bool
 		shouldClear []string
 	}{
 		{
@@ -97,6 +101,45 @@ func TestGetGlobalValue(t *testing.T) {
 			want:        "",
 			shouldClear: []string{},
 		},
+		{
+			name: "read from file",
+			key:  "filekey",
+			envVars: map[string]string{
+				"FILEKEY_FILE": "file",
+			},
+			want:        "file-value",
+			shouldClear: []string{"FILEKEY_FILE"},
+		},
+		{
+			name: "INPUT_ file before plain file",
+			key:  "filekey",
+			envVars: map[string]string{
+				"INPUT_FILEKEY_FILE": "file",
+				"FILEKEY_FILE":       "missing",
+			},
+			want:        "file-value",
+			shouldClear: []string{"INPUT_FILEKEY_FILE", "FILEKEY_FILE"},
+		},
+		{
+			name: "value before file",
+			key:  "filekey",
+			envVars: map[string]string{
+				"FILEKEY":      "env-value",
+				"FILEKEY_FILE": "file",
+			},
+			want:        "env-value",
+			shouldClear: []string{"FILEKEY", "FILEKEY_FILE"},
+		},
+		{
+			name: "unreadable file",
+			key:  "filekey",
+			envVars: map[string]string{
+				"FILEKEY_FILE": "missing",
+			},
+			want:        "",
+			wantErr:     true,
+			shouldClear: []string{"FILEKEY_FILE"},
+		},
 		{
 			name: "case insensitive key lookup",
 			key:  "MixedCase",
@@ -108,10 +151,20 @@ func TestGetGlobalValue(t *testing.T) {
 		},
 	}
 
+	// 文件变量中的 "file" 和 "missing" 指向临时目录中的文件
+	// "file" and "missing" in file variables name files in a temporary directory
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("file-value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Set environment variables
 			for k, v := range tt.envVars {
+				if strings.HasSuffix(k, "_FILE") {
+					v = filepath.Join(dir, v)
+				}
 				os.Setenv(k, v)
 			}
 
@@ -126,6 +179,77 @@ func TestGetGlobalValue(t *testing.T) {
 			if got != tt.want {
 				t.Errorf("GetGlobalValue(%q) = %v, want %v", tt.key, got, tt.want)
 			}
+
+			got, err := LookupGlobalValue(tt.key)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("LookupGlobalValue(%q) = %v, %v, want %v, error %v", tt.key, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "empty string",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "single value",
+			input: "released",
+			want:  []string{"released"},
+		},
+		{
+			name:  "trims whitespace and skips empty values",
+			input: " bug , ,released,",
+			want:  []string{"bug", "released"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToList(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToList(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name:  "empty string",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "pairs",
+			input: "PROJ=ws-a,OPS=ws-b",
+			want:  map[string]string{"PROJ": "ws-a", "OPS": "ws-b"},
+		},
+		{
+			name:  "trims whitespace and skips invalid pairs",
+			input: " PROJ = ws-a , OPS, =ws-c, WEB=",
+			want:  map[string]string{"PROJ": "ws-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToMap(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToMap(%q) = %v, want %v", tt.input, got, tt.want)
+			}
 		})
 	}
 }
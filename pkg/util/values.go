@@ -0,0 +1,230 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values reads typed settings from one or more lookup functions, such as
+// GetGlobalValue followed by a configuration file. The first lookup
+// returning a non-empty value wins, and the default is used when none does.
+// Invalid values fall back to the default and are collected, so every
+// problem can be reported at once by Err.
+type Values struct {
+	lookups []func(string) string
+	errs    []valueError
+}
+
+// 某个设置的错误
+// Error of a setting
+type valueError struct {
+	key string
+	err error
+}
+
+// ValueError is the error of an invalid setting. Reason identifies what was
+// expected, e.g. "expected_int", with Args as its arguments, so programs
+// can translate the error; Error formats it in English.
+type ValueError struct {
+	Key    string
+	Value  string
+	Reason string
+	Args   []any
+}
+
+// 各原因的英文说明
+// English description of each reason
+var valueReasons = map[string]string{
+	"expected_bool":     "expected true or false",
+	"expected_int":      "expected an integer",
+	"expected_min":      "expected at least %d",
+	"expected_duration": "expected a positive duration such as 30s or 1h",
+	"expected_enum":     "expected one of %s",
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s: invalid value %q, %s", e.Key, e.Value, fmt.Sprintf(valueReasons[e.Reason], e.Args...))
+}
+
+// NewValues returns Values reading from the given lookups in order.
+//
+// Parameters:
+//
+//	lookups - the functions returning the raw value of a key, highest precedence first.
+//
+// Returns:
+//
+//	*Values - the typed reader.
+func NewValues(lookups ...func(string) string) *Values {
+	return &Values{lookups: lookups}
+}
+
+// NewGlobalValues returns Values reading LookupGlobalValue first and then
+// the given lookups, such as a configuration file. Files named by
+// "*_FILE" variables that can't be read are reported by Err along with
+// invalid values.
+//
+// Parameters:
+//
+//	lookups - the functions returning the raw value of a key after the environment, highest precedence first.
+//
+// Returns:
+//
+//	*Values - the typed reader.
+func NewGlobalValues(lookups ...func(string) string) *Values {
+	v := &Values{}
+	v.lookups = append([]func(string) string{v.lookupGlobal}, lookups...)
+	return v
+}
+
+// 读取环境变量, 记录无法读取的文件
+// Read the environment, recording files that can't be read
+func (v *Values) lookupGlobal(key string) string {
+	value, err := LookupGlobalValue(key)
+	if err != nil {
+		v.errs = append(v.errs, valueError{key: key, err: err})
+	}
+	return value
+}
+
+// Lookup returns the raw value of key from the first lookup that has one.
+//
+// Parameters:
+//
+//	key - the key of the setting.
+//
+// Returns:
+//
+//	string - the raw value, or empty if no lookup has one.
+func (v *Values) Lookup(key string) string {
+	for _, lookup := range v.lookups {
+		if value := lookup(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// String returns the value of key, or def if it's unset.
+func (v *Values) String(key, def string) string {
+	if value := v.Lookup(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// Bool returns the boolean value of key, or def if it's unset. Accepted
+// values are those of strconv.ParseBool, case insensitive.
+func (v *Values) Bool(key string, def bool) bool {
+	value := v.Lookup(key)
+	if value == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(strings.ToLower(value))
+	if err != nil {
+		v.addError(key, value, "expected_bool")
+		return def
+	}
+	return b
+}
+
+// Int returns the integer value of key, or def if it's unset. Values
+// below min are invalid.
+func (v *Values) Int(key string, def, min int) int {
+	value := v.Lookup(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		v.addError(key, value, "expected_int")
+		return def
+	}
+	if n < min {
+		v.addError(key, value, "expected_min", min)
+		return def
+	}
+	return n
+}
+
+// Duration returns the positive duration value of key, e.g. "90s" or
+// "1h30m", or def if it's unset.
+func (v *Values) Duration(key string, def time.Duration) time.Duration {
+	value := v.Lookup(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		v.addError(key, value, "expected_duration")
+		return def
+	}
+	return d
+}
+
+// List returns the comma separated values of key as parsed by ToList, or
+// def if there are none.
+func (v *Values) List(key string, def []string) []string {
+	if list := ToList(v.Lookup(key)); list != nil {
+		return list
+	}
+	return def
+}
+
+// Map returns the key=value pairs of key as parsed by ToMap, or nil if
+// there are none.
+func (v *Values) Map(key string) map[string]string {
+	return ToMap(v.Lookup(key))
+}
+
+// Enum returns the value of key, which must be one of allowed (case
+// insensitive), or def if it's unset.
+//
+// Parameters:
+//
+//	key - the key of the setting.
+//	def - the default value.
+//	allowed - the valid values.
+//
+// Returns:
+//
+//	string - the matching allowed value as written in allowed, or def.
+func (v *Values) Enum(key, def string, allowed ...string) string {
+	value := v.Lookup(key)
+	if value == "" {
+		return def
+	}
+	if i := slices.IndexFunc(allowed, func(a string) bool { return strings.EqualFold(a, value) }); i >= 0 {
+		return allowed[i]
+	}
+	v.addError(key, value, "expected_enum", strings.Join(allowed, ", "))
+	return def
+}
+
+// Err returns the errors of the invalid values of keys read so far
+// joined together, or nil if there are none. Without keys it returns the
+// errors of all values.
+func (v *Values) Err(keys ...string) error {
+	return errors.Join(v.Errors(keys...)...)
+}
+
+// Errors is like Err, returning the errors one by one: a *ValueError for
+// each invalid value, and the error of each "*_FILE" file that can't be
+// read.
+func (v *Values) Errors(keys ...string) []error {
+	var errs []error
+	for _, e := range v.errs {
+		if len(keys) == 0 || slices.Contains(keys, e.key) {
+			errs = append(errs, e.err)
+		}
+	}
+	return errs
+}
+
+func (v *Values) addError(key, value, reason string, args ...any) {
+	v.errs = append(v.errs, valueError{key: key, err: &ValueError{Key: key, Value: value, Reason: reason, Args: args}})
+}
@@ -0,0 +1,136 @@
+package util
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValues(t *testing.T) {
+	env := map[string]string{
+		"NAME":     "env-name",
+		"DEBUG":    "TRUE",
+		"COUNT":    "8",
+		"TTL":      "90s",
+		"LABELS":   "a, b",
+		"MAP":      "PROJ=ws-a",
+		"OUTPUT":   "JSON",
+		"EMPTY":    "",
+		"BAD_BOOL": "maybe",
+		"BAD_INT":  "many",
+		"LOW_INT":  "0",
+		"BAD_TTL":  "-1s",
+		"BAD_ENUM": "xml",
+	}
+	file := map[string]string{"NAME": "file-name", "EMPTY": "file-empty", "FILE_ONLY": "file"}
+	v := NewValues(
+		func(key string) string { return env[key] },
+		func(key string) string { return file[key] },
+	)
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"env before file", v.String("NAME", "def"), "env-name"},
+		{"file when env is empty", v.String("EMPTY", "def"), "file-empty"},
+		{"file only", v.String("FILE_ONLY", "def"), "file"},
+		{"default", v.String("MISSING", "def"), "def"},
+		{"bool", v.Bool("DEBUG", false), true},
+		{"bool default", v.Bool("MISSING", true), true},
+		{"int", v.Int("COUNT", 4, 1), 8},
+		{"int default", v.Int("MISSING", 4, 1), 4},
+		{"duration", v.Duration("TTL", time.Hour), 90 * time.Second},
+		{"duration default", v.Duration("MISSING", time.Hour), time.Hour},
+		{"list", v.List("LABELS", nil), []string{"a", "b"}},
+		{"list default", v.List("MISSING", []string{"x"}), []string{"x"}},
+		{"map", v.Map("MAP"), map[string]string{"PROJ": "ws-a"}},
+		{"enum", v.Enum("OUTPUT", "text", "text", "json"), "json"},
+		{"enum default", v.Enum("MISSING", "text", "text", "json"), "text"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected no errors for valid values, got %v", err)
+	}
+
+	// 无效值使用默认值, 并一起报告
+	// Invalid values fall back to the default and are reported together
+	invalid := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"bool", v.Bool("BAD_BOOL", true), true},
+		{"int", v.Int("BAD_INT", 4, 1), 4},
+		{"int below min", v.Int("LOW_INT", 4, 1), 4},
+		{"duration", v.Duration("BAD_TTL", time.Hour), time.Hour},
+		{"enum", v.Enum("BAD_ENUM", "text", "text", "json"), "text"},
+	}
+	for _, tt := range invalid {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("invalid %s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	err := v.Err()
+	if err == nil {
+		t.Fatal("Expected errors for invalid values")
+	}
+	for _, want := range []string{`BAD_BOOL: invalid value "maybe"`, "BAD_INT:", "LOW_INT: invalid value \"0\", expected at least 1", "BAD_TTL:", "BAD_ENUM: invalid value \"xml\", expected one of text, json"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected errors to contain %q, got:\n%v", want, err)
+		}
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != len(invalid) {
+		t.Errorf("Expected %d errors, got %d:\n%v", len(invalid), n, err)
+	}
+
+	var valueErr *ValueError
+	if errs := v.Errors("LOW_INT"); len(errs) != 1 || !errors.As(errs[0], &valueErr) ||
+		valueErr.Reason != "expected_min" || !reflect.DeepEqual(valueErr.Args, []any{1}) {
+		t.Errorf("Expected a ValueError for LOW_INT, got %v", errs)
+	}
+
+	// 可以只查看部分设置的错误
+	// The errors of some settings can be checked on their own
+	if err := v.Err("BAD_INT", "NAME"); err == nil || strings.Contains(err.Error(), "BAD_BOOL") || !strings.Contains(err.Error(), "BAD_INT") {
+		t.Errorf("Expected only the BAD_INT error, got %v", err)
+	}
+	if err := v.Err("NAME", "MISSING"); err != nil {
+		t.Errorf("Expected no errors for valid keys, got %v", err)
+	}
+}
+
+func TestNewGlobalValues(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GLOBAL_NAME", "env-name")
+	t.Setenv("GLOBAL_TOKEN_FILE", filepath.Join(dir, "missing"))
+
+	file := map[string]string{"GLOBAL_NAME": "file-name", "GLOBAL_TOKEN": "file-token", "GLOBAL_OTHER": "file-other"}
+	v := NewGlobalValues(func(key string) string { return file[key] })
+
+	if got := v.String("GLOBAL_NAME", ""); got != "env-name" {
+		t.Errorf("Expected the environment before the file, got %q", got)
+	}
+	if got := v.String("GLOBAL_OTHER", ""); got != "file-other" {
+		t.Errorf("Expected the file value, got %q", got)
+	}
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected no errors before reading GLOBAL_TOKEN, got %v", err)
+	}
+
+	// 无法读取的 *_FILE 文件会被报告
+	// An unreadable *_FILE file is reported
+	v.String("GLOBAL_TOKEN", "")
+	if err := v.Err(); err == nil || !strings.Contains(err.Error(), "GLOBAL_TOKEN_FILE: open ") {
+		t.Errorf("Expected an error naming GLOBAL_TOKEN_FILE, got %v", err)
+	}
+}